@@ -0,0 +1,127 @@
+package collector
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// containerInstanceTypes are the instance types summed by
+// emitContainerTotals.
+var containerInstanceTypes = map[string]bool{
+	"docker":     true,
+	"podman":     true,
+	"containerd": true,
+	"incus":      true,
+	"k8s":        true,
+}
+
+// containerTotals accumulates counters across all interfaces of one container.
+type containerTotals struct {
+	app                  string
+	perspective          string
+	rxBytes, txBytes     uint64
+	rxPackets, txPackets uint64
+}
+
+// emitContainerTotals sums bytes/packets across all interfaces that resolve
+// to the same container (instance + instance_type) and emits one series per
+// container, so multi-network containers get a single stable number.
+// Unresolved veths (instance == interface name) are skipped. With
+// Options.ContainerPerspective the sums use the same swapped rx/tx as the
+// per-interface series.
+func (c *NetworkCollector) emitContainerTotals(ch chan<- prometheus.Metric, snap *snapshot) {
+	type key struct{ instance, instanceType string }
+	totals := make(map[key]*containerTotals)
+
+	for iface, s := range snap.stats {
+		info, ok := snap.info[iface]
+		if !ok || !containerInstanceTypes[info.InstanceType] || info.Instance == info.Name || !c.instanceTypeAllowed(info.InstanceType) {
+			continue
+		}
+		k := key{info.Instance, info.InstanceType}
+		t, ok := totals[k]
+		if !ok {
+			t = &containerTotals{app: info.App, perspective: c.perspectiveLabel(info)}
+			totals[k] = t
+		}
+		if c.containerPerspective(info) {
+			s = s.swapped()
+		}
+		t.rxBytes += s.RxBytes
+		t.txBytes += s.TxBytes
+		t.rxPackets += s.RxPackets
+		t.txPackets += s.TxPackets
+	}
+
+	for k, t := range totals {
+		labels := []string{k.instance, t.app, k.instanceType}
+		if c.opts.ContainerPerspective {
+			labels = append(labels, t.perspective)
+		}
+		ch <- prometheus.MustNewConstMetric(c.containerRxBytes, prometheus.CounterValue, float64(t.rxBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.containerTxBytes, prometheus.CounterValue, float64(t.txBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.containerRxPackets, prometheus.CounterValue, float64(t.rxPackets), labels...)
+		ch <- prometheus.MustNewConstMetric(c.containerTxPackets, prometheus.CounterValue, float64(t.txPackets), labels...)
+	}
+}
+
+// emitVLANTotals sums bytes across all interfaces that resolve to the same
+// VLAN ID and emits one series per VLAN. Interfaces without a VLAN are
+// skipped. Traffic crossing several interfaces of one VLAN (sub-interface,
+// bridge, tap) is counted on each of them.
+func (c *NetworkCollector) emitVLANTotals(ch chan<- prometheus.Metric, snap *snapshot) {
+	type totals struct{ rxBytes, txBytes uint64 }
+	byVLAN := make(map[string]*totals)
+
+	for iface, s := range snap.stats {
+		info, ok := snap.info[iface]
+		if !ok || info.VLAN == "" || !c.instanceTypeAllowed(info.InstanceType) {
+			continue
+		}
+		t, ok := byVLAN[info.VLAN]
+		if !ok {
+			t = &totals{}
+			byVLAN[info.VLAN] = t
+		}
+		t.rxBytes += s.RxBytes
+		t.txBytes += s.TxBytes
+	}
+
+	for vlan, t := range byVLAN {
+		ch <- prometheus.MustNewConstMetric(c.vlanRxBytes, prometheus.CounterValue, float64(t.rxBytes), vlan)
+		ch <- prometheus.MustNewConstMetric(c.vlanTxBytes, prometheus.CounterValue, float64(t.txBytes), vlan)
+	}
+}
+
+// emitCacheStats emits hit/miss counters for the enrichment caches that are
+// enabled: "docker_inspect" (summed over all Docker/Podman endpoints) and
+// "topology".
+func (c *NetworkCollector) emitCacheStats(ch chan<- prometheus.Metric) {
+	if c.opts.DockerCacheTTL > 0 && len(c.runtimes) > 0 {
+		var hits, misses uint64
+		for _, rt := range c.runtimes {
+			hits += rt.client.cacheHits.Load()
+			misses += rt.client.cacheMisses.Load()
+		}
+		ch <- prometheus.MustNewConstMetric(c.cacheHits, prometheus.CounterValue, float64(hits), "docker_inspect")
+		ch <- prometheus.MustNewConstMetric(c.cacheMisses, prometheus.CounterValue, float64(misses), "docker_inspect")
+	}
+	if c.opts.TopologyRefresh > 0 {
+		ch <- prometheus.MustNewConstMetric(c.cacheHits, prometheus.CounterValue, float64(c.topoCache.hits.Load()), "topology")
+		ch <- prometheus.MustNewConstMetric(c.cacheMisses, prometheus.CounterValue, float64(c.topoCache.misses.Load()), "topology")
+	}
+}
+
+// emitInterfaceCounts emits net_interface_count for every known instance
+// type, including zeros, so that churn (e.g. leaked veths) graphs cleanly.
+func (c *NetworkCollector) emitInterfaceCounts(ch chan<- prometheus.Metric, snap *snapshot) {
+	counts := make(map[string]int, len(instanceTypes))
+	for t := range instanceTypes {
+		counts[t] = 0
+	}
+	for iface := range snap.stats {
+		if info, ok := snap.info[iface]; ok {
+			counts[info.InstanceType]++
+		}
+	}
+	for t, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.interfaceCount, prometheus.GaugeValue, float64(n), t)
+	}
+}
@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestEmitContainerTotalsPerspective(t *testing.T) {
+	snap := &snapshot{
+		stats: map[string]interfaceStats{
+			"veth1": {RxBytes: 100, TxBytes: 1000, RxPackets: 1, TxPackets: 10},
+			"veth2": {RxBytes: 200, TxBytes: 2000, RxPackets: 2, TxPackets: 20},
+		},
+		info: map[string]interfaceInfo{
+			"veth1": {Name: "veth1", Instance: "web", App: "web", InstanceType: "docker"},
+			"veth2": {Name: "veth2", Instance: "web", App: "web", InstanceType: "docker"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		perspective bool
+		rxBytes     float64
+		txBytes     float64
+		rxPackets   float64
+		txPackets   float64
+		label       string
+	}{
+		{"host", false, 300, 3000, 3, 30, ""},
+		{"container", true, 3000, 300, 30, 3, "container"},
+	}
+	for _, tt := range tests {
+		c := testCollector(t, Options{ContainerTotals: true, ContainerPerspective: tt.perspective})
+		ch := make(chan prometheus.Metric, 8)
+		c.emitContainerTotals(ch, snap)
+		close(ch)
+
+		got := make(map[*prometheus.Desc]float64)
+		for m := range ch {
+			var pb dto.Metric
+			if err := m.Write(&pb); err != nil {
+				t.Fatal(err)
+			}
+			got[m.Desc()] = pb.GetCounter().GetValue()
+			if l := metricLabels(t, m)["perspective"]; l != tt.label {
+				t.Errorf("%s: perspective label = %q, want %q", tt.name, l, tt.label)
+			}
+		}
+		if got[c.containerRxBytes] != tt.rxBytes || got[c.containerTxBytes] != tt.txBytes {
+			t.Errorf("%s: rx/tx bytes = %v/%v, want %v/%v", tt.name, got[c.containerRxBytes], got[c.containerTxBytes], tt.rxBytes, tt.txBytes)
+		}
+		if got[c.containerRxPackets] != tt.rxPackets || got[c.containerTxPackets] != tt.txPackets {
+			t.Errorf("%s: rx/tx packets = %v/%v, want %v/%v", tt.name, got[c.containerRxPackets], got[c.containerTxPackets], tt.rxPackets, tt.txPackets)
+		}
+	}
+}
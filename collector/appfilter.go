@@ -0,0 +1,49 @@
+package collector
+
+// otherApp is the synthetic app (and instance) that container interfaces
+// outside Options.AppInclude are collapsed into.
+const otherApp = "other"
+
+// appCollapsed reports whether a container interface of the given type and
+// app should be folded into the "other" bucket by the app allowlist.
+func (c *NetworkCollector) appCollapsed(instanceType, app string) bool {
+	if c.appInclude == nil {
+		return false
+	}
+	if instanceType != "docker" && instanceType != "podman" {
+		return false
+	}
+	return !c.appInclude[app]
+}
+
+// otherInterfaceInfo returns the synthetic interface that collapsed
+// interfaces of instanceType are reported as.
+func otherInterfaceInfo(instanceType string) interfaceInfo {
+	return interfaceInfo{
+		Name:         otherApp,
+		Instance:     otherApp,
+		InstanceType: instanceType,
+		App:          otherApp,
+		State:        "unknown",
+	}
+}
+
+// add accumulates o's counters into s.
+func (s *interfaceStats) add(o interfaceStats) {
+	s.RxBytes += o.RxBytes
+	s.RxPackets += o.RxPackets
+	s.RxErrors += o.RxErrors
+	s.RxDropped += o.RxDropped
+	s.RxFifo += o.RxFifo
+	s.RxFrame += o.RxFrame
+	s.RxCompressed += o.RxCompressed
+	s.RxMulticast += o.RxMulticast
+	s.TxBytes += o.TxBytes
+	s.TxPackets += o.TxPackets
+	s.TxErrors += o.TxErrors
+	s.TxDropped += o.TxDropped
+	s.TxFifo += o.TxFifo
+	s.TxColls += o.TxColls
+	s.TxCarrier += o.TxCarrier
+	s.TxCompressed += o.TxCompressed
+}
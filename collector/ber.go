@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASN.1 BER tags of the types SNMP uses (RFC 3416).
+const (
+	asnInteger         = 0x02
+	asnOctetString     = 0x04
+	asnNull            = 0x05
+	asnOID             = 0x06
+	asnSequence        = 0x30
+	asnCounter32       = 0x41
+	asnGauge32         = 0x42
+	asnTimeTicks       = 0x43
+	asnCounter64       = 0x46
+	asnNoSuchObject    = 0x80
+	asnNoSuchInstance  = 0x81
+	asnEndOfMibView    = 0x82
+	asnGetRequest      = 0xa0
+	asnGetResponse     = 0xa2
+	asnGetBulkRequest  = 0xa5
+	asnReport          = 0xa8
+	berMaxLengthOctets = 4
+)
+
+var errBERTruncated = errors.New("ber: truncated element")
+
+// berLength encodes a definite length in short or long form.
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// berTLV encodes one element from its tag and the concatenated contents.
+func berTLV(tag byte, contents ...[]byte) []byte {
+	var value []byte
+	for _, c := range contents {
+		value = append(value, c...)
+	}
+	out := append([]byte{tag}, berLength(len(value))...)
+	return append(out, value...)
+}
+
+// berHeaderLen returns the length of the tag and length octets of an
+// element whose contents are n bytes long.
+func berHeaderLen(n int) int {
+	return 1 + len(berLength(n))
+}
+
+// berInt encodes v as an INTEGER in minimal two's complement form.
+func berInt(v int64) []byte {
+	n := 1
+	for x := v; x > 127 || x < -128; x >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return berTLV(asnInteger, b)
+}
+
+// berString encodes b as an OCTET STRING.
+func berString(b []byte) []byte {
+	return berTLV(asnOctetString, b)
+}
+
+// berOID encodes oid as an OBJECT IDENTIFIER. oid has at least two arcs.
+func berOID(oid []uint32) []byte {
+	var b []byte
+	for _, arc := range append([]uint32{40*oid[0] + oid[1]}, oid[2:]...) {
+		var enc []byte
+		enc = append(enc, byte(arc&0x7f))
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			enc = append([]byte{byte(arc&0x7f) | 0x80}, enc...)
+		}
+		b = append(b, enc...)
+	}
+	return berTLV(asnOID, b)
+}
+
+// berRead splits the first element off b.
+func berRead(b []byte) (tag byte, value, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errBERTruncated
+	}
+	tag = b[0]
+	n, i := int(b[1]), 2
+	if n&0x80 != 0 {
+		octets := n & 0x7f
+		if octets == 0 || octets > berMaxLengthOctets || len(b) < 2+octets {
+			return 0, nil, nil, fmt.Errorf("ber: unsupported length encoding")
+		}
+		n = 0
+		for _, c := range b[2 : 2+octets] {
+			n = n<<8 | int(c)
+		}
+		i = 2 + octets
+	}
+	if n > len(b)-i {
+		return 0, nil, nil, errBERTruncated
+	}
+	return tag, b[i : i+n], b[i+n:], nil
+}
+
+// berExpect splits the first element off b, which must carry tag.
+func berExpect(b []byte, tag byte) (value, rest []byte, err error) {
+	got, value, rest, err := berRead(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if got != tag {
+		return nil, nil, fmt.Errorf("ber: got tag 0x%02x, want 0x%02x", got, tag)
+	}
+	return value, rest, nil
+}
+
+// berReadInt splits an INTEGER off b.
+func berReadInt(b []byte) (int64, []byte, error) {
+	value, rest, err := berExpect(b, asnInteger)
+	if err != nil {
+		return 0, nil, err
+	}
+	v, err := berDecodeInt(value)
+	return v, rest, err
+}
+
+// berDecodeInt decodes the contents of a two's complement INTEGER.
+func berDecodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("ber: invalid integer length %d", len(b))
+	}
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+// berDecodeUint decodes the contents of an unsigned application type
+// (Counter32, Gauge32, TimeTicks, Counter64).
+func berDecodeUint(b []byte) (uint64, error) {
+	if len(b) == 0 || len(b) > 9 || (len(b) == 9 && b[0] != 0) {
+		return 0, fmt.Errorf("ber: invalid unsigned length %d", len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// berDecodeOID decodes the contents of an OBJECT IDENTIFIER.
+func berDecodeOID(b []byte) ([]uint32, error) {
+	if len(b) == 0 {
+		return nil, errors.New("ber: empty OID")
+	}
+	var arcs []uint32
+	var arc uint64
+	for i, c := range b {
+		arc = arc<<7 | uint64(c&0x7f)
+		if arc > 1<<32-1 {
+			return nil, errors.New("ber: OID arc overflows 32 bits")
+		}
+		if c&0x80 != 0 {
+			if i == len(b)-1 {
+				return nil, errBERTruncated
+			}
+			continue
+		}
+		if len(arcs) == 0 {
+			first := min(arc/40, 2)
+			arcs = append(arcs, uint32(first), uint32(arc-40*first))
+		} else {
+			arcs = append(arcs, uint32(arc))
+		}
+		arc = 0
+	}
+	return arcs, nil
+}
+
+// oidString formats oid in dotted notation.
+func oidString(oid []uint32) string {
+	parts := make([]string, len(oid))
+	for i, arc := range oid {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// oidHasPrefix reports whether oid lies in the subtree rooted at prefix.
+func oidHasPrefix(oid, prefix []uint32) bool {
+	if len(oid) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if oid[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// oidCompare orders OIDs lexicographically, as GETNEXT/GETBULK walk them.
+func oidCompare(a, b []uint32) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
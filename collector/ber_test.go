@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBERInt(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want string // hex encoding, "" to only check the round trip
+	}{
+		{0, "020100"},
+		{127, "02017f"},
+		{128, "02020080"},
+		{256, "02020100"},
+		{-1, "0201ff"},
+		{-128, "020180"},
+		{-129, "0202ff7f"},
+		{1 << 40, ""},
+		{math.MaxInt64, "02087fffffffffffffff"},
+		{math.MinInt64, "02088000000000000000"},
+	}
+	for _, tt := range tests {
+		enc := berInt(tt.v)
+		if tt.want != "" && hex.EncodeToString(enc) != tt.want {
+			t.Errorf("berInt(%d) = %x, want %s", tt.v, enc, tt.want)
+		}
+		got, rest, err := berReadInt(enc)
+		if err != nil || got != tt.v || len(rest) != 0 {
+			t.Errorf("berReadInt(%x) = %d, %x, %v; want %d", enc, got, rest, err, tt.v)
+		}
+	}
+}
+
+func TestBERLength(t *testing.T) {
+	tests := []struct {
+		n      int
+		header string
+	}{
+		{0, "0400"},
+		{127, "047f"},
+		{128, "048180"},
+		{255, "0481ff"},
+		{256, "04820100"},
+		{70000, "0483011170"},
+	}
+	for _, tt := range tests {
+		value := bytes.Repeat([]byte{0xaa}, tt.n)
+		enc := append(berString(value), 0x05, 0x00) // trailing NULL must be left over
+		if got := hex.EncodeToString(enc[:len(tt.header)/2]); got != tt.header {
+			t.Errorf("length %d: header %s, want %s", tt.n, got, tt.header)
+		}
+		if got := berHeaderLen(tt.n); got != len(tt.header)/2 {
+			t.Errorf("berHeaderLen(%d) = %d, want %d", tt.n, got, len(tt.header)/2)
+		}
+		tag, got, rest, err := berRead(enc)
+		if err != nil || tag != asnOctetString || !bytes.Equal(got, value) || !bytes.Equal(rest, []byte{0x05, 0x00}) {
+			t.Errorf("length %d: berRead = 0x%02x, %d bytes, rest %x, %v", tt.n, tag, len(got), rest, err)
+		}
+	}
+}
+
+func TestBERReadTruncated(t *testing.T) {
+	for _, in := range []string{"", "04", "0405aabb", "0482ff", "04850100000000"} {
+		b, _ := hex.DecodeString(in)
+		if _, _, _, err := berRead(b); err == nil {
+			t.Errorf("berRead(%s) succeeded, want error", in)
+		}
+	}
+}
+
+func TestBEROID(t *testing.T) {
+	tests := []struct {
+		oid  []uint32
+		want string
+	}{
+		{[]uint32{1, 3, 6, 1, 2, 1, 1, 1, 0}, "06082b06010201010100"},
+		{[]uint32{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6, 10101}, "060c2b060102011f01010106ce75"},
+		{[]uint32{2, 999, 3}, "0603883703"},
+		{[]uint32{1, 3, 6, 1, 4, 1, math.MaxUint32}, "060a2b06010401 8fffffff7f"},
+	}
+	for _, tt := range tests {
+		want, _ := hex.DecodeString(stripSpaces(tt.want))
+		enc := berOID(tt.oid)
+		if !bytes.Equal(enc, want) {
+			t.Errorf("berOID(%s) = %x, want %x", oidString(tt.oid), enc, want)
+		}
+		value, _, err := berExpect(enc, asnOID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := berDecodeOID(value)
+		if err != nil || !slices.Equal(got, tt.oid) {
+			t.Errorf("berDecodeOID(%x) = %v, %v; want %v", value, got, err, tt.oid)
+		}
+	}
+
+	if _, err := berDecodeOID([]byte{0x2b, 0x86}); err == nil {
+		t.Error("berDecodeOID accepted a truncated arc")
+	}
+	if _, err := berDecodeOID([]byte{0x2b, 0x90, 0x80, 0x80, 0x80, 0x00}); err == nil {
+		t.Error("berDecodeOID accepted an arc over 32 bits")
+	}
+}
+
+func stripSpaces(s string) string {
+	return strings.ReplaceAll(s, " ", "")
+}
+
+func TestBERCounter64(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{"4601 00", 0, false},
+		{"4602 0100", 256, false},
+		{"4608 0102030405060708", 0x0102030405060708, false},
+		{"4609 00ffffffffffffffff", math.MaxUint64, false},
+		{"4609 01ffffffffffffffff", 0, true},
+		{"4600", 0, true},
+	}
+	for _, tt := range tests {
+		b, _ := hex.DecodeString(stripSpaces(tt.in))
+		value, _, err := berExpect(b, asnCounter64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := berDecodeUint(value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("berDecodeUint(%s) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestOIDCompare(t *testing.T) {
+	a := []uint32{1, 3, 6, 1, 2}
+	b := []uint32{1, 3, 6, 1, 2, 1}
+	c := []uint32{1, 3, 6, 2}
+	if oidCompare(a, b) >= 0 || oidCompare(b, c) >= 0 || oidCompare(c, a) <= 0 || oidCompare(a, a) != 0 {
+		t.Error("oidCompare does not order OIDs lexicographically")
+	}
+	if !oidHasPrefix(b, a) || oidHasPrefix(a, b) || oidHasPrefix(c, a) {
+		t.Error("oidHasPrefix mismatch")
+	}
+}
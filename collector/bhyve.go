@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// buildBhyveMapping maps tap interfaces to bhyve VM names on FreeBSD
+// (TrueNAS CORE), where neither libvirt nor /proc/<PID>/fd exist.
+//
+// bhyve sets its process title to "bhyve: <vmname>", and FreeBSD's ifconfig
+// reports the PID holding each tap device open ("Opened by PID 1234"), so
+// joining the two gives tap → VM name.
+func (c *NetworkCollector) buildBhyveMapping() (map[string]string, error) {
+	result := make(map[string]string)
+
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "ps", "-axww", "-o", "pid=,command=")
+	var psOut bytes.Buffer
+	cmd.Stdout = &psOut
+	if err := cmd.Run(); err != nil {
+		return result, execError(ctx, c.logger, "ps", err)
+	}
+	vmByPID := parseBhyveProcesses(psOut.String())
+	if len(vmByPID) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel = c.execContext()
+	defer cancel()
+	cmd = c.buildCommand(ctx, "ifconfig", "-a")
+	var ifOut bytes.Buffer
+	cmd.Stdout = &ifOut
+	if err := cmd.Run(); err != nil {
+		return result, execError(ctx, c.logger, "ifconfig", err)
+	}
+	for iface, pid := range parseIfconfigTapOwners(ifOut.String()) {
+		if vmName, ok := vmByPID[pid]; ok {
+			result[iface] = vmName
+		}
+	}
+	return result, nil
+}
+
+// parseBhyveProcesses extracts PID → VM name from `ps -o pid=,command=`
+// output. It accepts both the process title form ("bhyve: myvm (bhyve)")
+// and a plain command line, where the VM name is the last argument.
+func parseBhyveProcesses(out string) map[int]string {
+	result := make(map[int]string)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		switch {
+		case fields[1] == "bhyve:" && len(fields) >= 3:
+			result[pid] = fields[2]
+		case strings.HasSuffix(fields[1], "/bhyve") || fields[1] == "bhyve":
+			if len(fields) >= 3 {
+				result[pid] = fields[len(fields)-1]
+			}
+		}
+	}
+	return result
+}
+
+// parseIfconfigTapOwners extracts tap interface → owning PID from FreeBSD
+// `ifconfig -a` output.
+func parseIfconfigTapOwners(out string) map[string]int {
+	result := make(map[string]int)
+	current := ""
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && line[0] != '\t' && line[0] != ' ' {
+			// Interface header: "tap0: flags=8943<UP,...> metric 0 mtu 1500"
+			current = ""
+			if name, _, ok := strings.Cut(line, ":"); ok && strings.HasPrefix(name, "tap") {
+				current = name
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(trimmed, "Opened by PID "); ok {
+			if pid, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil {
+				result[current] = pid
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// isBondMaster reports whether iface is a Linux bonding master, i.e. it has
+// a "bonding" directory in sysfs.
+func isBondMaster(sysNetPath, iface string) bool {
+	fi, err := os.Stat(filepath.Join(sysNetPath, iface, "bonding"))
+	return err == nil && fi.IsDir()
+}
+
+// buildBondMap returns a mapping from enslaved interface name → bond name.
+// Bond slaves carry the same "master" symlink as bridge ports, so the
+// master is checked for a bonding directory.
+func (c *NetworkCollector) buildBondMap(stats map[string]interfaceStats, sysNetPath string) map[string]string {
+	bondMap := make(map[string]string)
+	for iface := range stats {
+		target, err := os.Readlink(filepath.Join(sysNetPath, iface, "master"))
+		if err != nil {
+			continue
+		}
+		if master := filepath.Base(target); isBondMaster(sysNetPath, master) {
+			bondMap[iface] = master
+		}
+	}
+	return bondMap
+}
+
+// bondSlaveState holds the per-slave status read from
+// /sys/class/net/<slave>/bonding_slave/.
+type bondSlaveState struct {
+	Active bool // state == "active" (carrying traffic, not a backup)
+	LinkUp bool // mii_status == "up"
+}
+
+// readBondSlaveState reads the bonding_slave state of an enslaved NIC.
+func readBondSlaveState(sysNetPath, slave string) bondSlaveState {
+	dir := filepath.Join(sysNetPath, slave, "bonding_slave")
+	return bondSlaveState{
+		Active: readFileString(filepath.Join(dir, "state")) == "active",
+		LinkUp: readFileString(filepath.Join(dir, "mii_status")) == "up",
+	}
+}
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BuildInfoCollector exposes a constant net_exporter_build_info series
+// carrying the exporter's version, Go version and build date as labels.
+type BuildInfoCollector struct {
+	desc *prometheus.Desc
+}
+
+// NewBuildInfoCollector returns a collector for the build info metric.
+// namespace is the optional metric-name prefix (Options.MetricNamespace).
+func NewBuildInfoCollector(namespace, version, buildDate string) *BuildInfoCollector {
+	return &BuildInfoCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "net_exporter_build_info"),
+			"Build information of the running exporter (always 1).",
+			nil,
+			prometheus.Labels{
+				"version":   version,
+				"goversion": runtime.Version(),
+				"builddate": buildDate,
+			},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *BuildInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *BuildInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// classifyRule overrides the built-in classification of interfaces whose
+// name matches pattern.
+type classifyRule struct {
+	pattern      *regexp.Regexp
+	instanceType string
+	instance     string // template expanded with pattern's submatches; "" keeps the heuristic's
+	app          string // likewise
+}
+
+// loadClassifyRules reads a rules file with one rule per line:
+//
+//	<regexp> <instance_type> [instance=<name>] [app=<name>]
+//
+// Names may reference submatches of the regexp ($1, ${name}). Blank lines
+// and lines starting with "#" are ignored.
+func loadClassifyRules(path string) ([]classifyRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []classifyRule
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected <regexp> <instance_type> [instance=<name>] [app=<name>]", path, lineNo)
+		}
+		re, err := regexp.Compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		if !instanceTypes[fields[1]] {
+			return nil, fmt.Errorf("%s:%d: unknown instance type %q", path, lineNo, fields[1])
+		}
+		rule := classifyRule{pattern: re, instanceType: fields[1]}
+		for _, field := range fields[2:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "instance":
+				rule.instance = value
+			case "app":
+				rule.app = value
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown rule option %q (want instance= or app=)", path, lineNo, field)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// applyClassifyRules overrides info with the first rule matching its name.
+func (c *NetworkCollector) applyClassifyRules(info *interfaceInfo) {
+	for _, rule := range c.classifyRules {
+		match := rule.pattern.FindStringSubmatchIndex(info.Name)
+		if match == nil {
+			continue
+		}
+		info.InstanceType = rule.instanceType
+		if rule.instance != "" {
+			info.Instance = string(rule.pattern.ExpandString(nil, rule.instance, info.Name, match))
+		}
+		if rule.app != "" {
+			info.App = string(rule.pattern.ExpandString(nil, rule.app, info.Name, match))
+		}
+		return
+	}
+}
@@ -0,0 +1,301 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ContainerdClient is a minimal containerd API client that speaks gRPC
+// (HTTP/2 without TLS) over the containerd unix socket. It only implements
+// the calls needed to find running tasks and their PIDs: listing
+// namespaces, containers and tasks. Messages are encoded by hand with
+// protowire to avoid pulling in the gRPC and containerd modules.
+type ContainerdClient struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// containerdTask is one running task (a container's init process).
+type containerdTask struct {
+	ContainerID string
+	PID         int
+}
+
+// containerdK8sNamespace holds CRI-managed pods, which are mapped by
+// buildContainerdMapping under their pod names instead.
+const containerdK8sNamespace = "k8s.io"
+
+// NewContainerdClient creates a client for the containerd socket at
+// socketPath (e.g. /run/containerd/containerd.sock).
+func NewContainerdClient(socketPath string) *ContainerdClient {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	return &ContainerdClient{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Protocols: protocols,
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// call invokes a unary gRPC method with an encoded request message in the
+// given containerd namespace and returns the encoded response message.
+func (c *ContainerdClient) call(ctx context.Context, namespace, method string, msg []byte) ([]byte, error) {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://containerd"+method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if namespace != "" {
+		req.Header.Set("containerd-namespace", namespace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("containerd %s: HTTP %d", method, resp.StatusCode)
+	}
+
+	// Errors arrive as trailers, or as headers in a trailers-only response.
+	status, message := resp.Trailer.Get("grpc-status"), resp.Trailer.Get("grpc-message")
+	if status == "" {
+		status, message = resp.Header.Get("grpc-status"), resp.Header.Get("grpc-message")
+	}
+	if status != "0" {
+		return nil, fmt.Errorf("containerd %s: grpc status %s: %s", method, status, message)
+	}
+
+	if len(body) < 5 {
+		return nil, fmt.Errorf("containerd %s: short response", method)
+	}
+	if body[0] != 0 {
+		return nil, fmt.Errorf("containerd %s: compressed response not supported", method)
+	}
+	n := binary.BigEndian.Uint32(body[1:5])
+	if int(n) > len(body)-5 {
+		return nil, fmt.Errorf("containerd %s: truncated response", method)
+	}
+	return body[5 : 5+n], nil
+}
+
+// ListNamespaces returns the names of all containerd namespaces.
+func (c *ContainerdClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	resp, err := c.call(ctx, "", "/containerd.services.namespaces.v1.Namespaces/List", nil)
+	if err != nil {
+		return nil, err
+	}
+	// ListNamespacesResponse { repeated Namespace namespaces = 1; }
+	// Namespace { string name = 1; ... }
+	var names []string
+	err = forEachProtoField(resp, func(num protowire.Number, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		return forEachProtoField(v, func(num protowire.Number, v []byte) error {
+			if num == 1 {
+				names = append(names, string(v))
+			}
+			return nil
+		})
+	})
+	return names, err
+}
+
+// ListTasks returns the tasks in namespace that have a process.
+func (c *ContainerdClient) ListTasks(ctx context.Context, namespace string) ([]containerdTask, error) {
+	resp, err := c.call(ctx, namespace, "/containerd.services.tasks.v1.Tasks/List", nil)
+	if err != nil {
+		return nil, err
+	}
+	// ListTasksResponse { repeated containerd.v1.types.Process tasks = 1; }
+	// Process { string container_id = 1; string id = 2; uint32 pid = 3; ... }
+	var tasks []containerdTask
+	err = forEachProtoField(resp, func(num protowire.Number, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var t containerdTask
+		err := forEachProtoField(v, func(num protowire.Number, v []byte) error {
+			switch num {
+			case 1:
+				t.ContainerID = string(v)
+			case 3:
+				pid, n := protowire.ConsumeVarint(v)
+				if n < 0 {
+					return protowire.ParseError(n)
+				}
+				t.PID = int(pid)
+			}
+			return nil
+		})
+		if err == nil && t.PID > 0 {
+			tasks = append(tasks, t)
+		}
+		return err
+	})
+	return tasks, err
+}
+
+// ListContainerLabels returns the labels of every container in namespace,
+// keyed by container ID.
+func (c *ContainerdClient) ListContainerLabels(ctx context.Context, namespace string) (map[string]map[string]string, error) {
+	resp, err := c.call(ctx, namespace, "/containerd.services.containers.v1.Containers/List", nil)
+	if err != nil {
+		return nil, err
+	}
+	// ListContainersResponse { repeated Container containers = 1; }
+	// Container { string id = 1; map<string, string> labels = 2; ... }
+	result := make(map[string]map[string]string)
+	err = forEachProtoField(resp, func(num protowire.Number, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var id string
+		labels := make(map[string]string)
+		err := forEachProtoField(v, func(num protowire.Number, v []byte) error {
+			switch num {
+			case 1:
+				id = string(v)
+			case 2:
+				// Map entries are messages { string key = 1; string value = 2; }.
+				var key, value string
+				err := forEachProtoField(v, func(num protowire.Number, v []byte) error {
+					switch num {
+					case 1:
+						key = string(v)
+					case 2:
+						value = string(v)
+					}
+					return nil
+				})
+				labels[key] = value
+				return err
+			}
+			return nil
+		})
+		result[id] = labels
+		return err
+	})
+	return result, err
+}
+
+// forEachProtoField calls fn with the number and raw value of every field
+// in an encoded protobuf message. Length-delimited values are passed
+// without their length prefix; varints are passed still encoded.
+func forEachProtoField(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n >= 0 {
+				v = b[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchContainerdData maps host veths to containers from containerd's task
+// list, for hosts where containerd runs without a Docker-compatible API.
+// Every namespace except k8s.io is scanned; each task's init PID is
+// resolved to its host veths with the same iflink technique as Docker.
+func (c *NetworkCollector) fetchContainerdData(ifindexMap map[int]string, vethMap map[string]ContainerInfo) {
+	socket := c.containerd.socketPath
+	namespaces, err := c.containerd.ListNamespaces(c.ctx)
+	if err != nil {
+		c.warnFailure("containerd", "failed to list containerd namespaces", "socket", socket, "error", err)
+		c.recordError("containerd")
+		return
+	}
+	c.clearFailure("containerd", "listing containerd namespaces recovered", "socket", socket)
+
+	for _, ns := range namespaces {
+		if ns == containerdK8sNamespace {
+			continue
+		}
+		tasks, err := c.containerd.ListTasks(c.ctx, ns)
+		if err != nil {
+			c.logger.Debug("failed to list containerd tasks", "namespace", ns, "error", err)
+			c.recordError("containerd")
+			continue
+		}
+		labels, err := c.containerd.ListContainerLabels(c.ctx, ns)
+		if err != nil {
+			c.logger.Debug("failed to list containerd containers", "namespace", ns, "error", err)
+		}
+
+		for _, t := range tasks {
+			ci := ContainerInfo{
+				ID:      t.ContainerID,
+				Name:    containerdName(t.ContainerID, labels[t.ContainerID]),
+				PID:     t.PID,
+				Labels:  labels[t.ContainerID],
+				Runtime: "containerd",
+			}
+			for _, hostIfindex := range c.findContainerIflinks(c.opts.ProcPath, t.PID) {
+				hostIface, ok := ifindexMap[hostIfindex]
+				if !ok {
+					continue
+				}
+				if _, taken := vethMap[hostIface]; !taken {
+					vethMap[hostIface] = ci
+				}
+			}
+		}
+	}
+}
+
+// containerdName returns the nerdctl container name if set, otherwise the
+// container ID shortened to 12 characters like Docker does.
+func containerdName(id string, labels map[string]string) string {
+	if name := labels["nerdctl/name"]; name != "" {
+		return name
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
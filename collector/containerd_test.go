@@ -0,0 +1,204 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The fixtures below follow the containerd API protos:
+//
+//	namespaces.proto: ListNamespacesResponse { repeated Namespace namespaces = 1; }
+//	                  Namespace { string name = 1; map<string, string> labels = 2; }
+//	task.proto:       ListTasksResponse { repeated types.Process tasks = 1; }
+//	                  Process { string container_id = 1; string id = 2; uint32 pid = 3;
+//	                            Status status = 4; ... Timestamp exited_at = 10; }
+//	containers.proto: ListContainersResponse { repeated Container containers = 1; }
+//	                  Container { string id = 1; map<string, string> labels = 2;
+//	                              string image = 3; Runtime runtime = 4; ...
+//	                              Timestamp created_at = 8; }
+
+func protoString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func protoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func protoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func protoMap(b []byte, num protowire.Number, m map[string]string) []byte {
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		b = protoMessage(b, num, protoString(protoString(nil, 1, k), 2, m[k]))
+	}
+	return b
+}
+
+func testProcess(containerID string, pid uint32, status uint64) []byte {
+	b := protoString(nil, 1, containerID)
+	b = protoString(b, 2, containerID)
+	if pid != 0 {
+		b = protoVarint(b, 3, uint64(pid))
+	}
+	b = protoVarint(b, 4, status)
+	b = protoString(b, 5, "/run/containerd/fifo/stdin")
+	return protoMessage(b, 10, protoVarint(nil, 1, 1700000000))
+}
+
+func testContainer(id string, labels map[string]string) []byte {
+	b := protoString(nil, 1, id)
+	b = protoMap(b, 2, labels)
+	b = protoString(b, 3, "docker.io/library/nginx:latest")
+	b = protoMessage(b, 4, protoString(nil, 1, "io.containerd.runc.v2"))
+	return protoMessage(b, 8, protoVarint(nil, 1, 1700000000))
+}
+
+// fakeContainerd serves unary gRPC responses over h2c on a unix socket.
+// responses is keyed by namespace and method; a missing key answers with
+// grpc-status 5 (NOT_FOUND) in a trailers-only response.
+func fakeContainerd(t *testing.T, responses map[string][]byte) *ContainerdClient {
+	t.Helper()
+	// A short directory keeps the socket path under the 108-byte limit.
+	dir, err := os.MkdirTemp("", "ctrd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "containerd.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	srv := &http.Server{
+		Protocols: protocols,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Type") != "application/grpc" {
+				t.Errorf("%s: Content-Type = %q", r.URL.Path, r.Header.Get("Content-Type"))
+			}
+			req, _ := io.ReadAll(r.Body)
+			if len(req) != 5 || req[0] != 0 {
+				t.Errorf("%s: request frame = %x, want empty message", r.URL.Path, req)
+			}
+			w.Header().Set("Content-Type", "application/grpc")
+			msg, ok := responses[r.Header.Get("containerd-namespace")+r.URL.Path]
+			if !ok {
+				w.Header().Set("Grpc-Status", "5")
+				w.Header().Set("Grpc-Message", "not found")
+				return
+			}
+			frame := make([]byte, 5, 5+len(msg))
+			binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+			w.Write(append(frame, msg...))
+			w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+		}),
+	}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return NewContainerdClient(socket)
+}
+
+func TestContainerdListNamespaces(t *testing.T) {
+	var resp []byte
+	for _, name := range []string{"default", "k8s.io", "moby"} {
+		ns := protoString(nil, 1, name)
+		ns = protoMap(ns, 2, map[string]string{"containerd.io/defaults/snapshotter": "zfs"})
+		resp = protoMessage(resp, 1, ns)
+	}
+	client := fakeContainerd(t, map[string][]byte{
+		"/containerd.services.namespaces.v1.Namespaces/List": resp,
+	})
+
+	got, err := client.ListNamespaces(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"default", "k8s.io", "moby"}; !slices.Equal(got, want) {
+		t.Errorf("ListNamespaces = %q, want %q", got, want)
+	}
+}
+
+func TestContainerdListTasks(t *testing.T) {
+	var resp []byte
+	resp = protoMessage(resp, 1, testProcess("web", 4242, 2)) // RUNNING
+	resp = protoMessage(resp, 1, testProcess("db", 70000, 2)) // multi-byte varint
+	resp = protoMessage(resp, 1, testProcess("new", 0, 1))    // CREATED, no pid yet
+	client := fakeContainerd(t, map[string][]byte{
+		"default/containerd.services.tasks.v1.Tasks/List": resp,
+	})
+
+	got, err := client.ListTasks(context.Background(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []containerdTask{{"web", 4242}, {"db", 70000}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListTasks = %+v, want %+v", got, want)
+	}
+}
+
+func TestContainerdListContainerLabels(t *testing.T) {
+	webLabels := map[string]string{"nerdctl/name": "web", "io.containerd.image.config.stop-signal": "SIGQUIT"}
+	var resp []byte
+	resp = protoMessage(resp, 1, testContainer("0123456789abcdef", webLabels))
+	resp = protoMessage(resp, 1, testContainer("bare", nil))
+	client := fakeContainerd(t, map[string][]byte{
+		"default/containerd.services.containers.v1.Containers/List": resp,
+	})
+
+	got, err := client.ListContainerLabels(context.Background(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ListContainerLabels returned %d containers, want 2: %v", len(got), got)
+	}
+	if !maps.Equal(got["0123456789abcdef"], webLabels) {
+		t.Errorf("labels = %v, want %v", got["0123456789abcdef"], webLabels)
+	}
+	if l, ok := got["bare"]; !ok || len(l) != 0 {
+		t.Errorf("bare labels = %v, %v, want empty", l, ok)
+	}
+	if name := containerdName("0123456789abcdef", got["0123456789abcdef"]); name != "web" {
+		t.Errorf("containerdName = %q, want web", name)
+	}
+	if name := containerdName("0123456789abcdef", nil); name != "0123456789ab" {
+		t.Errorf("containerdName without label = %q, want 0123456789ab", name)
+	}
+}
+
+func TestContainerdGRPCError(t *testing.T) {
+	client := fakeContainerd(t, nil)
+	_, err := client.ListTasks(context.Background(), "default")
+	if err == nil || !strings.Contains(err.Error(), "grpc status 5: not found") {
+		t.Errorf("ListTasks error = %v, want grpc status 5", err)
+	}
+}
+
+func TestForEachProtoFieldMalformed(t *testing.T) {
+	// A length prefix that runs past the end of the message.
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendVarint(b, 10)
+	b = append(b, "short"...)
+	if err := forEachProtoField(b, func(protowire.Number, []byte) error { return nil }); err == nil {
+		t.Error("forEachProtoField accepted a truncated field")
+	}
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// interfaceCounter pairs one per-interface counter Desc with the
+// interfaceStats field it reports.
+type interfaceCounter struct {
+	desc  *prometheus.Desc
+	value func(s interfaceStats) uint64
+}
+
+// selectCounters returns the counters named in names (the metric name
+// without the "net_interface_" prefix and "_total" suffix, e.g. "rx_bytes"),
+// in their usual emission order. An empty list selects all of them.
+func (c *NetworkCollector) selectCounters(names []string) ([]interfaceCounter, error) {
+	all := []struct {
+		name string
+		interfaceCounter
+	}{
+		{"rx_bytes", interfaceCounter{c.rxBytes, func(s interfaceStats) uint64 { return s.RxBytes }}},
+		{"tx_bytes", interfaceCounter{c.txBytes, func(s interfaceStats) uint64 { return s.TxBytes }}},
+		{"rx_packets", interfaceCounter{c.rxPackets, func(s interfaceStats) uint64 { return s.RxPackets }}},
+		{"tx_packets", interfaceCounter{c.txPackets, func(s interfaceStats) uint64 { return s.TxPackets }}},
+		{"rx_errors", interfaceCounter{c.rxErrors, func(s interfaceStats) uint64 { return s.RxErrors }}},
+		{"tx_errors", interfaceCounter{c.txErrors, func(s interfaceStats) uint64 { return s.TxErrors }}},
+		{"rx_dropped", interfaceCounter{c.rxDropped, func(s interfaceStats) uint64 { return s.RxDropped }}},
+		{"tx_dropped", interfaceCounter{c.txDropped, func(s interfaceStats) uint64 { return s.TxDropped }}},
+		{"rx_fifo", interfaceCounter{c.rxFifo, func(s interfaceStats) uint64 { return s.RxFifo }}},
+		{"rx_frame", interfaceCounter{c.rxFrame, func(s interfaceStats) uint64 { return s.RxFrame }}},
+		{"rx_compressed", interfaceCounter{c.rxCompressed, func(s interfaceStats) uint64 { return s.RxCompressed }}},
+		{"rx_multicast", interfaceCounter{c.rxMulticast, func(s interfaceStats) uint64 { return s.RxMulticast }}},
+		{"tx_fifo", interfaceCounter{c.txFifo, func(s interfaceStats) uint64 { return s.TxFifo }}},
+		{"tx_collisions", interfaceCounter{c.txColls, func(s interfaceStats) uint64 { return s.TxColls }}},
+		{"tx_carrier_errors", interfaceCounter{c.txCarrier, func(s interfaceStats) uint64 { return s.TxCarrier }}},
+		{"tx_compressed", interfaceCounter{c.txCompressed, func(s interfaceStats) uint64 { return s.TxCompressed }}},
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var counters []interfaceCounter
+	for _, ctr := range all {
+		if len(names) == 0 || wanted[ctr.name] {
+			counters = append(counters, ctr.interfaceCounter)
+			delete(wanted, ctr.name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown counter %q in counter filter", name)
+	}
+	return counters, nil
+}
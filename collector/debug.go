@@ -0,0 +1,30 @@
+package collector
+
+// EnrichmentTrace is a full dump of one enrichment pass: the resolved
+// metadata of every interface and the intermediate mappings it was built
+// from. It is served as JSON by /debug/interfaces.
+type EnrichmentTrace struct {
+	Interfaces      map[string]interfaceInfo     `json:"interfaces"`
+	BridgeMap       map[string]string            `json:"bridge_map"`
+	BondMap         map[string]string            `json:"bond_map"`
+	IfindexMap      map[int]string               `json:"ifindex_map"`
+	VethToContainer map[string]ContainerInfo     `json:"veth_to_container"`
+	BridgeToNetwork map[string]DockerNetworkInfo `json:"bridge_to_network"`
+	VethToIncus     map[string]string            `json:"veth_to_incus"`
+	VethToPod       map[string]string            `json:"veth_to_pod"`
+	VnetToVM        map[string]string            `json:"vnet_to_vm"`
+	VLANMap         map[string]vlanInfo          `json:"vlan_map"`
+}
+
+// TraceEnrichment reads the current counters and runs a fresh enrichment
+// pass, bypassing the background snapshot, and returns everything it
+// resolved.
+func (c *NetworkCollector) TraceEnrichment() (*EnrichmentTrace, error) {
+	stats, _, err := c.readStats()
+	if err != nil {
+		return nil, err
+	}
+	trace := &EnrichmentTrace{}
+	trace.Interfaces = c.buildInterfaceInfo(stats, trace)
+	return trace, nil
+}
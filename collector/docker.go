@@ -2,32 +2,111 @@ package collector
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
 // DockerClient is a minimal Docker Engine API client that communicates
-// over the Docker unix socket.  It only implements the subset of the API
-// needed to list containers and inspect their network settings.
+// over the Docker unix socket or a TCP endpoint.  It only implements the
+// subset of the API needed to list containers and inspect their network
+// settings.
 type DockerClient struct {
 	socketPath string
+	// baseURL is the scheme and host every API path is appended to
+	// ("http://localhost" for unix sockets).
+	baseURL    string
 	httpClient *http.Client
+	opts       DockerClientOptions
+	// remote is set for TCP and HTTP(S) endpoints, whose container PIDs
+	// belong to another host's procfs.
+	remote bool
+
+	// apiVersion is the Engine API version negotiated by Available and
+	// prefixed to every request path ("" until negotiated).
+	versionMu  sync.Mutex
+	apiVersion string
+
+	// inspectCache remembers inspect results by container ID.
+	cacheMu      sync.Mutex
+	inspectCache map[string]cachedInspect
+
+	// cacheHits and cacheMisses count inspectCache lookups.
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+
+	// openConns counts connections to the daemon that have been dialed
+	// and not yet closed, idle keep-alive connections included.
+	openConns atomic.Int64
+}
+
+// DockerClientOptions tunes DockerClient behaviour. The zero value disables
+// all caching.
+type DockerClientOptions struct {
+	// InspectCacheTTL is how long a container's inspect result is reused
+	// before it is fetched again.
+	InspectCacheTTL time.Duration
+
+	// InspectConcurrency bounds how many container inspect requests run in
+	// parallel (values below 1 mean serial inspection).
+	InspectConcurrency int
+
+	// MaxRetries is how many times a list or inspect request is retried
+	// after a transient failure (timeout, reset connection, HTTP 5xx).
+	// Permanent failures such as a refused connection are not retried.
+	MaxRetries int
+
+	// TLSConfig, when set, is used for tcp:// endpoints, which are then
+	// spoken to over HTTPS. It is ignored for unix sockets.
+	TLSConfig *tls.Config
+
+	// ObserveRequest, when set, is called after every API request with the
+	// endpoint name ("version", "containers", "inspect", "networks"), the
+	// HTTP status code ("error" if no response arrived) and the latency.
+	// Each retry counts as a separate request.
+	ObserveRequest func(endpoint, status string, d time.Duration)
+}
+
+// fallbackDockerAPIVersion is used when the daemon's API version cannot be
+// negotiated. 1.41 (Docker 20.10) is also what Podman's compat API reports.
+const fallbackDockerAPIVersion = "1.41"
+
+// cachedInspect is one inspect result together with when it was fetched.
+type cachedInspect struct {
+	info      ContainerInfo
+	fetchedAt time.Time
 }
 
 // ContainerInfo holds the subset of Docker inspect data we care about.
 type ContainerInfo struct {
-	ID    string
-	Name  string
-	PID   int
+	ID   string
+	Name string
+	PID  int
 	// Networks maps Docker network name → endpoint information.
 	Networks map[string]ContainerNetwork
 	// Labels from the container (used for compose project detection).
 	Labels map[string]string
+	// Runtime is the daemon the container was discovered through
+	// ("docker", "podman" or "containerd"); set by the collector, not the API.
+	Runtime string
+	// RestartCount is how often the daemon has restarted the container
+	// under its restart policy.
+	RestartCount int
+	// HostNetwork is set for containers running with network_mode: host,
+	// which share the host's network namespace and own no veth.
+	HostNetwork bool
 }
 
 // ContainerNetwork holds per-network endpoint information for a container.
@@ -37,49 +116,238 @@ type ContainerNetwork struct {
 	IPAddress  string
 }
 
-// NewDockerClient creates a client connected to the given Docker socket path.
-// The socketPath should be the absolute path on the host (e.g. /var/run/docker.sock)
-// or the container-mapped path (e.g. /host/var/run/docker.sock).
-func NewDockerClient(socketPath string) *DockerClient {
-	transport := &http.Transport{
-		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-			return net.DialTimeout("unix", socketPath, 5*time.Second)
-		},
+// NewDockerClient creates a client connected to the given Docker endpoint.
+// The endpoint is either a unix socket path — the absolute path on the host
+// (e.g. /var/run/docker.sock) or the container-mapped path (e.g.
+// /host/var/run/docker.sock), optionally prefixed with unix:// — or a remote
+// daemon given as tcp://host:port, http://host:port or https://host:port.
+func NewDockerClient(socketPath string, opts DockerClientOptions) *DockerClient {
+	c := &DockerClient{
+		socketPath:   socketPath,
+		opts:         opts,
+		inspectCache: make(map[string]cachedInspect),
 	}
-	return &DockerClient{
-		socketPath: socketPath,
-		httpClient: &http.Client{
-			Transport: transport,
-			Timeout:   10 * time.Second,
-		},
+	transport := &http.Transport{}
+	baseURL := "http://localhost"
+	dial := (&net.Dialer{Timeout: 5 * time.Second}).DialContext
+
+	c.remote = true
+	switch {
+	case strings.HasPrefix(socketPath, "tcp://"):
+		host := strings.TrimPrefix(socketPath, "tcp://")
+		if opts.TLSConfig != nil {
+			transport.TLSClientConfig = opts.TLSConfig
+			baseURL = "https://" + host
+		} else {
+			baseURL = "http://" + host
+		}
+	case strings.HasPrefix(socketPath, "http://"):
+		baseURL = strings.TrimSuffix(socketPath, "/")
+	case strings.HasPrefix(socketPath, "https://"):
+		transport.TLSClientConfig = opts.TLSConfig
+		baseURL = strings.TrimSuffix(socketPath, "/")
+	default:
+		c.remote = false
+		path := strings.TrimPrefix(socketPath, "unix://")
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, "unix", path)
+		}
 	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		c.openConns.Add(1)
+		return &trackedConn{Conn: conn, open: &c.openConns}, nil
+	}
+
+	c.baseURL = baseURL
+	c.httpClient = &http.Client{
+		Transport: transport,
+		Timeout:   10 * time.Second,
+	}
+	return c
+}
+
+// trackedConn decrements open once when the connection is closed.
+type trackedConn struct {
+	net.Conn
+	once sync.Once
+	open *atomic.Int64
 }
 
-// Available checks whether the Docker socket is reachable.
-func (c *DockerClient) Available() bool {
-	resp, err := c.httpClient.Get("http://localhost/version")
+func (t *trackedConn) Close() error {
+	t.once.Do(func() { t.open.Add(-1) })
+	return t.Conn.Close()
+}
+
+// Remote reports whether the daemon is reached over the network rather
+// than a unix socket. Its container PIDs cannot be resolved in the local
+// procfs.
+func (c *DockerClient) Remote() bool {
+	return c.remote
+}
+
+// OpenConnections returns the number of connections to the daemon currently
+// open, idle keep-alive connections included. A value that keeps growing
+// while the daemon flaps points to leaked response bodies.
+func (c *DockerClient) OpenConnections() int64 {
+	return c.openConns.Load()
+}
+
+// LoadDockerTLSConfig builds a mutual-TLS client configuration for a remote
+// Docker daemon from PEM files. certFile and keyFile must be given together;
+// caFile, if set, replaces the system roots for verifying the daemon.
+// It returns nil when no files are given.
+func LoadDockerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("docker TLS: certificate and key must be set together")
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("docker TLS: load key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("docker TLS: read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("docker TLS: no certificates found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// get issues a GET request for an API path, bound to ctx, and reports it
+// to ObserveRequest under endpoint.
+func (c *DockerClient) get(ctx context.Context, endpoint, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
 	if err != nil {
-		return false
+		return nil, err
 	}
-	resp.Body.Close()
-	return resp.StatusCode == http.StatusOK
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if c.opts.ObserveRequest != nil {
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		c.opts.ObserveRequest(endpoint, status, time.Since(start))
+	}
+	return resp, err
 }
 
-// ListContainers returns information about all running containers.
-func (c *DockerClient) ListContainers() ([]ContainerInfo, error) {
-	// List running containers.
-	resp, err := c.httpClient.Get("http://localhost/containers/json")
+// retryBaseDelay is the backoff before the first retry; it doubles on
+// each further attempt (100ms, 200ms, 400ms, ...). Tests shorten it.
+var retryBaseDelay = 100 * time.Millisecond
+
+// getBody GETs an API path and returns the response body and status code,
+// retrying transient failures up to MaxRetries times with exponential
+// backoff. All attempts share one client timeout, so retries never stretch
+// a request beyond what a single attempt could take.
+func (c *DockerClient) getBody(ctx context.Context, endpoint, path string) ([]byte, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.httpClient.Timeout)
+	defer cancel()
+
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		body, status, err := c.getBodyOnce(ctx, endpoint, path)
+		retry := (err != nil && isTransientDockerError(err)) || (err == nil && status >= 500)
+		if !retry || attempt >= c.opts.MaxRetries {
+			return body, status, err
+		}
+		select {
+		case <-ctx.Done():
+			return body, status, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// getBodyOnce performs a single GET and reads the whole response body.
+func (c *DockerClient) getBodyOnce(ctx context.Context, endpoint, path string) ([]byte, int, error) {
+	resp, err := c.get(ctx, endpoint, path)
 	if err != nil {
-		return nil, fmt.Errorf("docker list containers: %w", err)
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
-
 	body, err := io.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
+
+// isTransientDockerError reports whether err is worth retrying. A refused
+// connection or a missing socket means the daemon is down, and a cancelled
+// context means the caller gave up; neither will improve on retry.
+func isTransientDockerError(err error) bool {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ENOENT),
+		errors.Is(err, context.Canceled):
+		return false
+	}
+	return true
+}
+
+// Available checks whether the Docker socket is reachable and records the
+// daemon's API version for subsequent requests.
+func (c *DockerClient) Available(ctx context.Context) bool {
+	resp, err := c.get(ctx, "version", "/version")
 	if err != nil {
-		return nil, fmt.Errorf("docker read response: %w", err)
+		return false
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("docker API returned %d: %s", resp.StatusCode, string(body))
+		return false
+	}
+
+	var v struct {
+		APIVersion string `json:"ApiVersion"`
+	}
+	version := fallbackDockerAPIVersion
+	if err := json.NewDecoder(resp.Body).Decode(&v); err == nil && v.APIVersion != "" {
+		version = v.APIVersion
+	}
+	// Drain any trailing bytes so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	c.versionMu.Lock()
+	c.apiVersion = version
+	c.versionMu.Unlock()
+	return true
+}
+
+// apiPath prefixes path with the negotiated API version, e.g.
+// "/containers/json" → "/v1.43/containers/json".
+func (c *DockerClient) apiPath(path string) string {
+	c.versionMu.Lock()
+	version := c.apiVersion
+	c.versionMu.Unlock()
+	if version == "" {
+		version = fallbackDockerAPIVersion
+	}
+	return "/v" + version + path
+}
+
+// ListContainers returns information about all running containers.
+func (c *DockerClient) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	// List running containers.
+	body, status, err := c.getBody(ctx, "containers", c.apiPath("/containers/json"))
+	if err != nil {
+		return nil, fmt.Errorf("docker list containers: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("docker API returned %d: %s", status, string(body))
 	}
 
 	var containers []dockerContainerListEntry
@@ -87,32 +355,103 @@ func (c *DockerClient) ListContainers() ([]ContainerInfo, error) {
 		return nil, fmt.Errorf("docker unmarshal list: %w", err)
 	}
 
+	// Inspect containers across a bounded worker pool. The shared context
+	// caps the whole fan-out at the client timeout so one hung inspect
+	// cannot stall the collection.
+	ctx, cancel := context.WithTimeout(ctx, c.httpClient.Timeout)
+	defer cancel()
+
+	workers := c.opts.InspectConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	infos := make([]ContainerInfo, len(containers))
+	ok := make([]bool, len(containers))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, c2 := range containers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			info, err := c.cachedInspectContainer(ctx, id)
+			if err != nil {
+				// Skip containers that disappear between list and inspect.
+				return
+			}
+			infos[i], ok[i] = info, true
+		}(i, c2.ID)
+	}
+	wg.Wait()
+
 	var result []ContainerInfo
-	for _, c2 := range containers {
-		info, err := c.inspectContainer(c2.ID)
-		if err != nil {
-			// Skip containers that disappear between list and inspect.
-			continue
+	running := make(map[string]bool, len(containers))
+	for i, c2 := range containers {
+		running[c2.ID] = true
+		if ok[i] {
+			result = append(result, infos[i])
 		}
-		result = append(result, info)
 	}
+	c.pruneInspectCache(running)
 	return result, nil
 }
 
-// inspectContainer retrieves full container details via the inspect API.
-func (c *DockerClient) inspectContainer(id string) (ContainerInfo, error) {
-	resp, err := c.httpClient.Get(fmt.Sprintf("http://localhost/containers/%s/json", id))
-	if err != nil {
-		return ContainerInfo{}, fmt.Errorf("docker inspect %s: %w", id, err)
+// cachedInspectContainer returns the cached inspect result for id if it is
+// younger than InspectCacheTTL, otherwise inspects the container and caches
+// the result.
+func (c *DockerClient) cachedInspectContainer(ctx context.Context, id string) (ContainerInfo, error) {
+	if c.opts.InspectCacheTTL <= 0 {
+		return c.inspectContainer(ctx, id)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	c.cacheMu.Lock()
+	entry, ok := c.inspectCache[id]
+	c.cacheMu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < c.opts.InspectCacheTTL {
+		c.cacheHits.Add(1)
+		return entry.info, nil
+	}
+	c.cacheMisses.Add(1)
+
+	info, err := c.inspectContainer(ctx, id)
 	if err != nil {
 		return ContainerInfo{}, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return ContainerInfo{}, fmt.Errorf("docker inspect %s returned %d", id, resp.StatusCode)
+	c.cacheMu.Lock()
+	c.inspectCache[id] = cachedInspect{info: info, fetchedAt: time.Now()}
+	c.cacheMu.Unlock()
+	return info, nil
+}
+
+// pruneInspectCache drops cached entries for containers that are no longer
+// in the running list.
+func (c *DockerClient) pruneInspectCache(running map[string]bool) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	for id := range c.inspectCache {
+		if !running[id] {
+			delete(c.inspectCache, id)
+		}
+	}
+}
+
+// InvalidateContainer forgets any cached inspect result for id, forcing the
+// next ListContainers to inspect it again (e.g. after a restart changed its PID).
+func (c *DockerClient) InvalidateContainer(id string) {
+	c.cacheMu.Lock()
+	delete(c.inspectCache, id)
+	c.cacheMu.Unlock()
+}
+
+// inspectContainer retrieves full container details via the inspect API.
+func (c *DockerClient) inspectContainer(ctx context.Context, id string) (ContainerInfo, error) {
+	body, status, err := c.getBody(ctx, "inspect", c.apiPath("/containers/"+id+"/json"))
+	if err != nil {
+		return ContainerInfo{}, fmt.Errorf("docker inspect %s: %w", id, err)
+	}
+	if status != http.StatusOK {
+		return ContainerInfo{}, fmt.Errorf("docker inspect %s returned %d", id, status)
 	}
 
 	var raw dockerInspectResponse
@@ -132,26 +471,40 @@ func (c *DockerClient) inspectContainer(id string) (ContainerInfo, error) {
 	}
 
 	return ContainerInfo{
-		ID:       raw.ID,
-		Name:     name,
-		PID:      raw.State.PID,
-		Networks: networks,
-		Labels:   raw.Config.Labels,
+		ID:           raw.ID,
+		Name:         name,
+		PID:          raw.State.PID,
+		Networks:     networks,
+		Labels:       raw.Config.Labels,
+		RestartCount: raw.RestartCount,
+		HostNetwork:  raw.HostConfig.NetworkMode == "host",
 	}, nil
 }
 
+// composeServiceLabel names the service within a Docker Compose project.
+const composeServiceLabel = "com.docker.compose.service"
+
+// defaultAppPrefixes are stripped from project and network names unless
+// configured otherwise: TrueNAS apps use "ix-<appname>".
+var defaultAppPrefixes = []string{"ix-"}
+
 // AppName extracts a human-friendly application name from the container.
 // It uses the Docker Compose project label if available, otherwise the
-// container name with common prefixes stripped.
+// container name with the TrueNAS "ix-" prefix stripped.
 func AppName(c ContainerInfo) string {
+	return AppNameWithPrefixes(c, defaultAppPrefixes)
+}
+
+// AppNameWithPrefixes is AppName with the first matching prefix in
+// prefixes stripped instead of "ix-".
+func AppNameWithPrefixes(c ContainerInfo, prefixes []string) string {
 	// Docker Compose v2 label.
 	if project, ok := c.Labels["com.docker.compose.project"]; ok {
-		// TrueNAS apps use "ix-<appname>" as project.
-		return strings.TrimPrefix(project, "ix-")
+		name, _ := trimAppPrefix(project, prefixes)
+		return name
 	}
-	// Fallback: strip common TrueNAS prefixes from container name.
-	name := c.Name
-	name = strings.TrimPrefix(name, "ix-")
+	// Fallback: strip the prefix from the container name.
+	name, _ := trimAppPrefix(c.Name, prefixes)
 	// Remove trailing instance numbers like "-1".
 	if idx := strings.LastIndex(name, "-"); idx > 0 {
 		suffix := name[idx+1:]
@@ -162,6 +515,17 @@ func AppName(c ContainerInfo) string {
 	return name
 }
 
+// trimAppPrefix strips the first of prefixes that s starts with, reporting
+// whether one matched.
+func trimAppPrefix(s string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			return rest, true
+		}
+	}
+	return s, false
+}
+
 func isNumeric(s string) bool {
 	for _, c := range s {
 		if c < '0' || c > '9' {
@@ -181,8 +545,10 @@ type dockerContainerListEntry struct {
 type dockerInspectResponse struct {
 	ID              string `json:"Id"`
 	Name            string
+	RestartCount    int
 	State           dockerState
 	Config          dockerConfig
+	HostConfig      dockerHostConfig
 	NetworkSettings dockerNetworkSettings
 }
 
@@ -194,6 +560,10 @@ type dockerConfig struct {
 	Labels map[string]string
 }
 
+type dockerHostConfig struct {
+	NetworkMode string
+}
+
 type dockerNetworkSettings struct {
 	Networks map[string]dockerEndpoint
 }
@@ -210,11 +580,27 @@ type DockerNetworkInfo struct {
 	Name       string
 	Driver     string
 	BridgeName string // host bridge interface name (e.g., "br-2c852816592c" or "docker0")
+	Parent     string // host interface a macvlan/ipvlan network rides on (e.g., "eno1.100")
+	Internal   bool   // no external connectivity (created with --internal)
+	Attachable bool   // standalone containers may attach (swarm-scoped networks)
+	// IPAM holds the network's address pools (typically one IPv4 and,
+	// if enabled, one IPv6 entry).
+	IPAM []DockerIPAMConfig
+}
+
+// DockerIPAMConfig is one address pool of a Docker network.
+type DockerIPAMConfig struct {
+	Subnet  string
+	Gateway string
 }
 
-// ListNetworks returns information about all Docker bridge networks.
-func (c *DockerClient) ListNetworks() ([]DockerNetworkInfo, error) {
-	resp, err := c.httpClient.Get("http://localhost/networks")
+// ListNetworks returns information about all Docker networks tied to a host
+// interface: bridge networks with their bridge, and macvlan/ipvlan networks
+// with their parent interface. Other drivers (overlay, host, none) and
+// macvlan/ipvlan networks without a parent have no host interface and are
+// skipped.
+func (c *DockerClient) ListNetworks(ctx context.Context) ([]DockerNetworkInfo, error) {
+	resp, err := c.get(ctx, "networks", c.apiPath("/networks"))
 	if err != nil {
 		return nil, fmt.Errorf("docker list networks: %w", err)
 	}
@@ -235,15 +621,28 @@ func (c *DockerClient) ListNetworks() ([]DockerNetworkInfo, error) {
 
 	var result []DockerNetworkInfo
 	for _, n := range raw {
-		if n.Driver != "bridge" {
+		switch n.Driver {
+		case "bridge":
+		case "macvlan", "ipvlan":
+			if n.Options["parent"] == "" {
+				continue
+			}
+		default:
 			continue
 		}
 		info := DockerNetworkInfo{
-			ID:     n.ID,
-			Name:   n.Name,
-			Driver: n.Driver,
+			ID:         n.ID,
+			Name:       n.Name,
+			Driver:     n.Driver,
+			Internal:   n.Internal,
+			Attachable: n.Attachable,
+		}
+		for _, cfg := range n.IPAM.Config {
+			info.IPAM = append(info.IPAM, DockerIPAMConfig{Subnet: cfg.Subnet, Gateway: cfg.Gateway})
 		}
-		if name, ok := n.Options["com.docker.network.bridge.name"]; ok {
+		if n.Driver != "bridge" {
+			info.Parent = n.Options["parent"]
+		} else if name, ok := n.Options["com.docker.network.bridge.name"]; ok {
 			info.BridgeName = name
 		} else if len(n.ID) >= 12 {
 			info.BridgeName = "br-" + n.ID[:12]
@@ -254,8 +653,16 @@ func (c *DockerClient) ListNetworks() ([]DockerNetworkInfo, error) {
 }
 
 type dockerNetworkListEntry struct {
-	ID      string            `json:"Id"`
-	Name    string            `json:"Name"`
-	Driver  string            `json:"Driver"`
-	Options map[string]string `json:"Options"`
+	ID         string            `json:"Id"`
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Internal   bool              `json:"Internal"`
+	Attachable bool              `json:"Attachable"`
+	Options    map[string]string `json:"Options"`
+	IPAM       struct {
+		Config []struct {
+			Subnet  string `json:"Subnet"`
+			Gateway string `json:"Gateway"`
+		} `json:"Config"`
+	} `json:"IPAM"`
 }
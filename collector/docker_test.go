@@ -0,0 +1,268 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const testNetworksJSON = `[
+  {"Id": "2c852816592c0123456789", "Name": "ix-app_default", "Driver": "bridge", "Internal": true,
+   "Options": {}, "IPAM": {"Config": [{"Subnet": "172.16.1.0/24", "Gateway": "172.16.1.1"}]}},
+  {"Id": "aaaa", "Name": "bridge", "Driver": "bridge",
+   "Options": {"com.docker.network.bridge.name": "docker0"}},
+  {"Id": "bbbb", "Name": "lan100", "Driver": "macvlan",
+   "Options": {"parent": "eno1.100"}, "IPAM": {"Config": [{"Subnet": "192.168.100.0/24"}]}},
+  {"Id": "cccc", "Name": "l3net", "Driver": "ipvlan", "Options": {"parent": "eno2", "ipvlan_mode": "l3"}},
+  {"Id": "dddd", "Name": "isolated", "Driver": "macvlan", "Options": {}},
+  {"Id": "eeee", "Name": "swarm", "Driver": "overlay", "Attachable": true},
+  {"Id": "ffff", "Name": "host", "Driver": "host"}
+]`
+
+func TestListNetworks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/networks") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testNetworksJSON))
+	}))
+	defer srv.Close()
+
+	networks, err := NewDockerClient(srv.URL, DockerClientOptions{}).ListNetworks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]DockerNetworkInfo)
+	for _, n := range networks {
+		got[n.Name] = n
+	}
+
+	tests := []struct {
+		name                   string
+		driver, bridge, parent string
+		internal               bool
+	}{
+		{"ix-app_default", "bridge", "br-2c852816592c", "", true},
+		{"bridge", "bridge", "docker0", "", false},
+		{"lan100", "macvlan", "", "eno1.100", false},
+		{"l3net", "ipvlan", "", "eno2", false},
+	}
+	for _, tt := range tests {
+		n, ok := got[tt.name]
+		if !ok {
+			t.Errorf("network %s missing", tt.name)
+			continue
+		}
+		if n.Driver != tt.driver || n.BridgeName != tt.bridge || n.Parent != tt.parent || n.Internal != tt.internal {
+			t.Errorf("network %s = driver %q, bridge %q, parent %q, internal %v; want %q, %q, %q, %v",
+				tt.name, n.Driver, n.BridgeName, n.Parent, n.Internal, tt.driver, tt.bridge, tt.parent, tt.internal)
+		}
+	}
+	for _, name := range []string{"isolated", "swarm", "host"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("network %s has no host interface but was listed", name)
+		}
+	}
+	if len(got["lan100"].IPAM) != 1 || got["lan100"].IPAM[0].Subnet != "192.168.100.0/24" {
+		t.Errorf("lan100 IPAM = %+v", got["lan100"].IPAM)
+	}
+
+	// The macvlan network is reported on its parent interface.
+	c := testCollector(t, Options{})
+	lan := got["lan100"]
+	ch := make(chan prometheus.Metric, 1)
+	c.emitDockerNetworkInfo(ch, lan.Parent, &lan)
+	close(ch)
+	labels := metricLabels(t, <-ch)
+	if labels["driver"] != "macvlan" || labels["bridge"] != "eno1.100" || labels["network"] != "lan100" {
+		t.Errorf("net_docker_network_info labels = %v, want driver=macvlan bridge=eno1.100 network=lan100", labels)
+	}
+}
+
+// TestNetDriverLabel checks that bridges and macvlan parents carry their
+// Docker network driver in the net_driver label.
+func TestNetDriverLabel(t *testing.T) {
+	dir, err := os.MkdirTemp("", "dock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/version"):
+			w.Write([]byte(`{"ApiVersion": "1.43"}`))
+		case strings.HasSuffix(r.URL.Path, "/networks"):
+			w.Write([]byte(testNetworksJSON))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	rootfs := t.TempDir()
+	ifaces := []string{"br-2c852816592c", "eno1.100", "eno2", "lo"}
+	stats := make(map[string]interfaceStats)
+	for i, iface := range ifaces {
+		d := filepath.Join(rootfs, "sys", "class", "net", iface)
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(d, "ifindex"), []byte(strconv.Itoa(i+1)), 0o644)
+		stats[iface] = interfaceStats{}
+	}
+
+	opts := Options{
+		ProcPath: t.TempDir(), RootfsPath: rootfs, NetDriverLabel: true,
+		DisableVM: true, DisableIncus: true, DisableVLAN: true,
+	}
+	c, err := NewNetworkCollector(context.Background(), slog.New(slog.DiscardHandler), opts, []string{"unix://" + socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := c.buildInterfaceInfo(stats, nil)
+
+	names := interfaceLabelNames(opts)
+	if names[len(names)-1] != "net_driver" {
+		t.Fatalf("label names = %v, want net_driver last", names)
+	}
+	want := map[string]string{"br-2c852816592c": "bridge", "eno1.100": "macvlan", "eno2": "ipvlan", "lo": ""}
+	for iface, driver := range want {
+		values := c.interfaceLabelValues(info[iface])
+		if got := values[len(values)-1]; got != driver {
+			t.Errorf("%s: net_driver = %q, want %q", iface, got, driver)
+		}
+	}
+}
+
+// TestFetchRuntimeDataRemote checks that a remote daemon's networks are
+// mapped but its containers are never listed, since their PIDs would be
+// resolved against the exporter's own procfs.
+func TestFetchRuntimeDataRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/version"):
+			w.Write([]byte(`{"ApiVersion": "1.43"}`))
+		case strings.HasSuffix(r.URL.Path, "/networks"):
+			w.Write([]byte(testNetworksJSON))
+		default:
+			t.Errorf("unexpected request to remote daemon: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := testCollector(t, Options{})
+	rt := containerRuntime{name: "docker", endpoint: srv.URL, client: NewDockerClient(srv.URL, DockerClientOptions{}), up: new(atomic.Bool)}
+	if !rt.client.Remote() {
+		t.Fatalf("%s: Remote() = false", srv.URL)
+	}
+	vethMap := make(map[string]ContainerInfo)
+	netMap := make(map[string]DockerNetworkInfo)
+	if !c.fetchRuntimeData(rt, map[int]string{1: "lo"}, vethMap, netMap, make(map[string]string)) {
+		t.Fatal("fetchRuntimeData reported the daemon as down")
+	}
+	if len(vethMap) != 0 {
+		t.Errorf("vethMap = %v, want empty for a remote daemon", vethMap)
+	}
+	if netMap["docker0"].Name != "bridge" || netMap["eno1.100"].Name != "lan100" {
+		t.Errorf("netMap = %v, want docker0 and eno1.100 mapped", netMap)
+	}
+
+	for _, endpoint := range []string{"/var/run/docker.sock", "unix:///run/user/1000/docker.sock"} {
+		if NewDockerClient(endpoint, DockerClientOptions{}).Remote() {
+			t.Errorf("%s: Remote() = true", endpoint)
+		}
+	}
+}
+
+// TestDockerClientNoConnectionLeak checks that failed inspects and retried
+// requests close their response bodies: the open connection count stays
+// bounded by the inspect concurrency and drops to zero once idle
+// connections are closed.
+func TestDockerClientNoConnectionLeak(t *testing.T) {
+	defer func(d time.Duration) { retryBaseDelay = d }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	dir, err := os.MkdirTemp("", "dock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/version"):
+			w.Write([]byte(`{"ApiVersion": "1.43"}`))
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			w.Write([]byte(`[{"Id": "gone1"}, {"Id": "gone2"}, {"Id": "broken1"}, {"Id": "broken2"}]`))
+		case strings.Contains(r.URL.Path, "/containers/gone"):
+			http.Error(w, `{"message": "No such container"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"message": "server error"}`, http.StatusInternalServerError)
+		}
+	}))
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	const workers = 4
+	client := NewDockerClient("unix://"+socket, DockerClientOptions{InspectConcurrency: workers, MaxRetries: 2})
+	ctx := context.Background()
+	if !client.Available(ctx) {
+		t.Fatal("fake daemon not available")
+	}
+	var peak int64
+	for range 200 {
+		if _, err := client.inspectContainer(ctx, "broken1"); err == nil {
+			t.Fatal("inspect of a failing container succeeded")
+		}
+		if _, err := client.inspectContainer(ctx, "gone1"); err == nil {
+			t.Fatal("inspect of a missing container succeeded")
+		}
+		containers, err := client.ListContainers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(containers) != 0 {
+			t.Fatalf("ListContainers = %v, want none", containers)
+		}
+		peak = max(peak, client.OpenConnections())
+	}
+	if peak > workers {
+		t.Errorf("open connections peaked at %d, want at most %d", peak, workers)
+	}
+
+	client.httpClient.CloseIdleConnections()
+	deadline := time.Now().Add(time.Second)
+	for client.OpenConnections() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := client.OpenConnections(); n != 0 {
+		t.Errorf("%d connections still open after CloseIdleConnections", n)
+	}
+}
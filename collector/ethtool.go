@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EthtoolCollector exposes NIC driver statistics from `ethtool -S` for
+// physical interfaces. Stat names vary per driver, so metric descriptors are
+// created at collection time and the collector is unchecked.
+type EthtoolCollector struct {
+	ctx         context.Context
+	opts        Options
+	logger      *slog.Logger
+	ifaceFilter interfaceFilter
+}
+
+// NewEthtoolCollector returns a collector that runs `ethtool -S` for every
+// physical interface passing the interface include/exclude filters on each
+// scrape. Cancelling ctx kills any ethtool process still running.
+func NewEthtoolCollector(ctx context.Context, logger *slog.Logger, opts Options) (*EthtoolCollector, error) {
+	ifaceFilter, err := newInterfaceFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &EthtoolCollector{ctx: ctx, opts: opts, logger: logger, ifaceFilter: ifaceFilter}, nil
+}
+
+// Describe implements prometheus.Collector. It sends no descriptors, making
+// this an unchecked collector since stat names are only known at runtime.
+func (c *EthtoolCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *EthtoolCollector) Collect(ch chan<- prometheus.Metric) {
+	sysNetPath := c.opts.sysClassNetPath()
+	entries, err := os.ReadDir(sysNetPath)
+	if err != nil {
+		c.logger.Debug("cannot list interfaces for ethtool", "path", sysNetPath, "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		iface := entry.Name()
+		if !c.ifaceFilter.allowed(iface) {
+			continue
+		}
+		// Physical interfaces only: they have a device/driver symlink.
+		target, err := os.Readlink(filepath.Join(sysNetPath, iface, "device", "driver"))
+		if err != nil {
+			continue
+		}
+		driver := filepath.Base(target)
+
+		stats, err := c.runEthtoolStats(iface)
+		if err != nil {
+			c.logger.Debug("ethtool -S failed", "interface", iface, "error", err)
+			continue
+		}
+		for name, value := range stats {
+			desc := prometheus.NewDesc(
+				prometheus.BuildFQName(c.opts.MetricNamespace, "", "net_interface_ethtool_"+name),
+				"Driver statistic "+name+" from ethtool -S.",
+				[]string{"interface", "driver"}, nil,
+			)
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), iface, driver)
+		}
+	}
+}
+
+// runEthtoolStats runs `ethtool -S <iface>` and returns its statistics with
+// names sanitized for use in metric names.
+func (c *EthtoolCollector) runEthtoolStats(iface string) (map[string]uint64, error) {
+	ctx, cancel := c.opts.execContext(c.ctx)
+	defer cancel()
+	cmd := c.opts.buildCommand(ctx, "ethtool", "-S", iface)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return nil, execError(ctx, c.logger, "ethtool", err)
+	}
+	return parseEthtoolStats(out.String()), nil
+}
+
+// parseEthtoolStats parses `ethtool -S` output:
+//
+//	NIC statistics:
+//	     rx_packets: 1234
+//	     rx_queue_0_bytes: 5678
+//
+// Non-numeric values are skipped. Duplicate names after sanitization keep
+// the first value.
+func parseEthtoolStats(out string) map[string]uint64 {
+	result := make(map[string]uint64)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		name = sanitizeMetricName(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, dup := result[name]; !dup {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// sanitizeMetricName lowercases s and replaces every character not valid in
+// a Prometheus metric name with '_'.
+func sanitizeMetricName(s string) string {
+	b := []byte(strings.ToLower(s))
+	for i, ch := range b {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_') {
+			b[i] = '_'
+		}
+	}
+	return strings.Trim(string(b), "_")
+}
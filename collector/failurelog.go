@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// failureSummaryInterval is how often a failure that keeps recurring on
+// every scrape is logged again at warn level, with a count of the repeats
+// suppressed in between.
+const failureSummaryInterval = 10 * time.Minute
+
+// failureLog deduplicates warnings for enrichment failures that persist
+// across scrapes (e.g. Docker being down), keyed by failure source.
+type failureLog struct {
+	mu     sync.Mutex
+	active map[string]*failureState
+}
+
+// failureState tracks one ongoing failure.
+type failureState struct {
+	since      time.Time // first occurrence
+	lastLogged time.Time // last warn-level log
+	repeats    int       // occurrences suppressed since lastLogged
+}
+
+// warnFailure logs msg at warn level the first time key fails and then at
+// most once per failureSummaryInterval, adding how long the failure has
+// lasted and how many repeats were suppressed. Suppressed repeats are
+// still logged at debug level.
+func (c *NetworkCollector) warnFailure(key, msg string, args ...any) {
+	now := time.Now()
+	c.failures.mu.Lock()
+	defer c.failures.mu.Unlock()
+	if c.failures.active == nil {
+		c.failures.active = make(map[string]*failureState)
+	}
+
+	st, ok := c.failures.active[key]
+	if !ok {
+		c.failures.active[key] = &failureState{since: now, lastLogged: now}
+		c.logger.Warn(msg, args...)
+		return
+	}
+	st.repeats++
+	if now.Sub(st.lastLogged) < failureSummaryInterval {
+		c.logger.Debug(msg, args...)
+		return
+	}
+	c.logger.Warn(msg, append(args, "failing_for", now.Sub(st.since).Round(time.Second), "repeats", st.repeats)...)
+	st.lastLogged = now
+	st.repeats = 0
+}
+
+// clearFailure marks key as healthy again, logging msg at info level if it
+// was failing.
+func (c *NetworkCollector) clearFailure(key, msg string, args ...any) {
+	c.failures.mu.Lock()
+	defer c.failures.mu.Unlock()
+	st, ok := c.failures.active[key]
+	if !ok {
+		return
+	}
+	delete(c.failures.active, key)
+	c.logger.Info(msg, append(args, "failed_for", time.Since(st.since).Round(time.Second))...)
+}
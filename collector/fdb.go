@@ -0,0 +1,21 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// fdbEntrySize is sizeof(struct __fdb_entry), the record format of the
+// binary /sys/class/net/<bridge>/brforward file.
+const fdbEntrySize = 16
+
+// readFDBEntryCount returns the number of forwarding database entries
+// (learned and local MACs) of a Linux bridge. ok is false when the bridge
+// has no brforward file, e.g. an Open vSwitch bridge.
+func readFDBEntryCount(sysNetPath, bridge string) (n int, ok bool) {
+	data, err := os.ReadFile(filepath.Join(sysNetPath, bridge, "brforward"))
+	if err != nil {
+		return 0, false
+	}
+	return len(data) / fdbEntrySize, true
+}
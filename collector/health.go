@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// HealthStatus describes whether the collector's data sources are usable.
+type HealthStatus struct {
+	// CounterSource is the counters file, sysfs directory or "netlink"
+	// that was checked: the one the configured stats backend reads.
+	CounterSource string
+	// CounterErr is non-nil when CounterSource cannot be read. Without it
+	// no metrics can be produced, so it is the only hard failure.
+	CounterErr error
+	// HostCountersErr is set when the procfs backend cannot read the host's
+	// counters and CounterSource is the <ProcPath>/net/dev fallback.
+	HostCountersErr error
+	// Runtimes maps each configured container runtime endpoint, as
+	// "<runtime> (<endpoint>)", to whether its API answered. These are
+	// optional enrichment sources.
+	Runtimes map[string]bool
+}
+
+// Healthy reports whether the collector can produce metrics.
+func (h HealthStatus) Healthy() bool {
+	return h.CounterErr == nil
+}
+
+// Health checks that the configured counter source is readable and pings
+// each configured container runtime socket.
+func (c *NetworkCollector) Health() HealthStatus {
+	status := HealthStatus{Runtimes: make(map[string]bool)}
+	status.CounterSource, status.HostCountersErr, status.CounterErr = c.opts.checkCounterSource()
+	for _, rt := range c.runtimes {
+		status.Runtimes[rt.name+" ("+rt.endpoint+")"] = rt.client.Available(c.ctx)
+	}
+	return status
+}
+
+// checkCounterSource reports the source the configured stats backend reads
+// counters from and whether it is readable, the way readStats will find
+// it: the procfs backend falls back to <ProcPath>/net/dev when the host's
+// file is unreadable, in which case hostErr says why.
+func (o Options) checkCounterSource() (source string, hostErr, err error) {
+	switch o.StatsBackend {
+	case StatsBackendNetlink:
+		_, err := readNetlinkStats()
+		return "netlink", nil, err
+	case StatsBackendSysfs:
+		source = o.sysClassNetPath()
+		_, err := os.ReadDir(source)
+		return source, nil, err
+	}
+	source = o.hostProcNet("dev")
+	hostErr = readable(source)
+	if hostErr == nil {
+		return source, nil, nil
+	}
+	fallback := filepath.Join(o.ProcPath, "net", "dev")
+	if readable(fallback) == nil {
+		return fallback, hostErr, nil
+	}
+	return source, hostErr, hostErr
+}
+
+// readable reports why path cannot be opened for reading, if it cannot.
+func readable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthCounterSource(t *testing.T) {
+	netDev := "Inter-|\n face |\n  eth0: 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16\n"
+	mkfile := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(netDev), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hostProc := t.TempDir()
+	mkfile(filepath.Join(hostProc, "1", "net", "dev"))
+	fallbackProc := t.TempDir()
+	mkfile(filepath.Join(fallbackProc, "net", "dev"))
+	rootfs := t.TempDir()
+	mkfile(filepath.Join(rootfs, "sys", "class", "net", "eth0", "statistics", "rx_bytes"))
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name         string
+		opts         Options
+		source       string
+		healthy      bool
+		hostCounters bool // HostCountersErr set
+	}{
+		{"procfs", Options{ProcPath: hostProc}, filepath.Join(hostProc, "1", "net", "dev"), true, false},
+		{"procfs fallback", Options{ProcPath: fallbackProc}, filepath.Join(fallbackProc, "net", "dev"), true, true},
+		{"procfs missing", Options{ProcPath: missing}, filepath.Join(missing, "1", "net", "dev"), false, true},
+		{"sysfs", Options{ProcPath: missing, RootfsPath: rootfs, StatsBackend: StatsBackendSysfs}, filepath.Join(rootfs, "sys", "class", "net"), true, false},
+		{"sysfs missing", Options{ProcPath: missing, RootfsPath: missing, StatsBackend: StatsBackendSysfs}, filepath.Join(missing, "sys", "class", "net"), false, false},
+	}
+	for _, tt := range tests {
+		status := testCollector(t, tt.opts).Health()
+		if status.CounterSource != tt.source || status.Healthy() != tt.healthy || (status.HostCountersErr != nil) != tt.hostCounters {
+			t.Errorf("%s: source %q, healthy %v, host counters error %v; want %q, %v, %v",
+				tt.name, status.CounterSource, status.Healthy(), status.HostCountersErr, tt.source, tt.healthy, tt.hostCounters)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// testCollector returns a collector for opts reading the local /proc.
+func testCollector(t *testing.T, opts Options) *NetworkCollector {
+	t.Helper()
+	opts.ProcPath = cmp.Or(opts.ProcPath, "/proc")
+	opts.RootfsPath = cmp.Or(opts.RootfsPath, "/")
+	c, err := NewNetworkCollector(context.Background(), slog.New(slog.DiscardHandler), opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// metricLabels returns m's labels by name.
+func metricLabels(t *testing.T, m prometheus.Metric) map[string]string {
+	t.Helper()
+	var pb dto.Metric
+	if err := m.Write(&pb); err != nil {
+		t.Fatal(err)
+	}
+	labels := make(map[string]string)
+	for _, l := range pb.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
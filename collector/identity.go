@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// identityForgetAfter is how long an interface name's last ifindex is kept
+// after the interface disappears, so a name reused shortly afterwards (e.g.
+// vnet3 handed to a different VM across a reboot) is still detected.
+const identityForgetAfter = time.Hour
+
+// identityTracker remembers the ifindex each interface name had when it was
+// last seen. The kernel assigns a fresh ifindex whenever a device is
+// created, so a changed ifindex for the same name means the interface was
+// recreated and its series may now describe a different device.
+type identityTracker struct {
+	mu   sync.Mutex
+	seen map[string]identityEntry
+}
+
+type identityEntry struct {
+	ifindex  int
+	lastSeen time.Time
+}
+
+// checkInterfaceIdentity compares every interface's current ifindex with the
+// one recorded previously and logs a warning for each name that now refers
+// to a new device. When any changed, the cached topology is dropped so
+// ifindex-based mappings are rebuilt. It reports whether a change was seen.
+func (c *NetworkCollector) checkInterfaceIdentity(stats map[string]interfaceStats) bool {
+	sysNetPath := c.sysClassNetPath()
+	now := time.Now()
+
+	c.identity.mu.Lock()
+	defer c.identity.mu.Unlock()
+	if c.identity.seen == nil {
+		c.identity.seen = make(map[string]identityEntry)
+	}
+
+	changed := false
+	for iface := range stats {
+		idx, err := strconv.Atoi(readFileString(filepath.Join(sysNetPath, iface, "ifindex")))
+		if err != nil {
+			continue
+		}
+		if prev, ok := c.identity.seen[iface]; ok && prev.ifindex != idx {
+			c.logger.Warn("interface recreated with a new ifindex; its series may now describe a different device",
+				"interface", iface, "old_ifindex", prev.ifindex, "new_ifindex", idx)
+			changed = true
+		}
+		c.identity.seen[iface] = identityEntry{ifindex: idx, lastSeen: now}
+	}
+	for iface, entry := range c.identity.seen {
+		if now.Sub(entry.lastSeen) > identityForgetAfter {
+			delete(c.identity.seen, iface)
+		}
+	}
+
+	if changed {
+		c.invalidateTopology()
+	}
+	return changed
+}
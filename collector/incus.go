@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// IncusClient is a minimal Incus/LXD REST API client over the daemon's
+// unix socket. It only lists instances together with their runtime state.
+type IncusClient struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// IncusInstance is the subset of an Incus instance's state we need to map
+// its host-side interfaces.
+type IncusInstance struct {
+	Name string
+	Type string // "container" or "virtual-machine"
+	PID  int
+	// HostInterfaces are the host-side device names of the instance's NICs
+	// (e.g. "veth1a2b3c4d"), as reported by the daemon.
+	HostInterfaces []string
+}
+
+// NewIncusClient creates a client for the Incus socket at socketPath
+// (e.g. /var/run/incus/unix.socket).
+func NewIncusClient(socketPath string) *IncusClient {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.DialTimeout("unix", socketPath, 5*time.Second)
+		},
+	}
+	return &IncusClient{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+	}
+}
+
+// ListInstances returns all running instances with their init PID and
+// host-side interface names.
+func (c *IncusClient) ListInstances(ctx context.Context) ([]IncusInstance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://incus/1.0/instances?recursion=2", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("incus list instances: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("incus read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("incus API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw incusInstancesResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("incus unmarshal instances: %w", err)
+	}
+
+	var result []IncusInstance
+	for _, inst := range raw.Metadata {
+		if inst.Status != "Running" || inst.State == nil {
+			continue
+		}
+		ii := IncusInstance{Name: inst.Name, Type: inst.Type, PID: inst.State.PID}
+		for _, nic := range inst.State.Network {
+			if nic.HostName != "" {
+				ii.HostInterfaces = append(ii.HostInterfaces, nic.HostName)
+			}
+		}
+		result = append(result, ii)
+	}
+	return result, nil
+}
+
+// -- internal JSON types matching the Incus API response --
+
+type incusInstancesResponse struct {
+	Metadata []incusInstance `json:"metadata"`
+}
+
+type incusInstance struct {
+	Name   string              `json:"name"`
+	Type   string              `json:"type"`
+	Status string              `json:"status"`
+	State  *incusInstanceState `json:"state"`
+}
+
+type incusInstanceState struct {
+	PID     int                         `json:"pid"`
+	Network map[string]incusNetworkInfo `json:"network"`
+}
+
+type incusNetworkInfo struct {
+	HostName string `json:"host_name"`
+}
+
+// buildIncusAPIMapping maps host-side interfaces to Incus instance names
+// using the daemon's API. NICs without a reported host name fall back to
+// the iflink technique on the instance's init PID.
+func (c *NetworkCollector) buildIncusAPIMapping(ifindexMap map[int]string) (map[string]string, error) {
+	instances, err := c.incus.ListInstances(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, inst := range instances {
+		for _, iface := range inst.HostInterfaces {
+			result[iface] = inst.Name
+		}
+		if len(inst.HostInterfaces) > 0 || inst.PID <= 0 || inst.Type != "container" {
+			continue
+		}
+		for _, hostIfindex := range c.findContainerIflinks(c.opts.ProcPath, inst.PID) {
+			if hostIface, ok := ifindexMap[hostIfindex]; ok {
+				result[hostIface] = inst.Name
+			}
+		}
+	}
+	return result, nil
+}
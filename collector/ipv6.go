@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"os"
+	"strconv"
+)
+
+// readIPv6AddressCounts parses <ProcPath>/<HostPID>/net/if_inet6 and returns the
+// number of IPv6 addresses held by each host interface. The owning ifindex
+// (second column, hex) is resolved through ifindexMap. ok is false when the
+// file is absent, e.g. on hosts booted with IPv6 disabled.
+func (c *NetworkCollector) readIPv6AddressCounts(ifindexMap map[int]string) (counts map[string]int, ok bool) {
+	path := c.opts.hostProcNet("if_inet6")
+	if _, err := os.Stat(path); err != nil {
+		c.logger.Debug("cannot read IPv6 addresses", "path", path, "error", err)
+		return nil, false
+	}
+
+	counts = make(map[string]int)
+	err := readProcNetColumns(path, func(fields []string) {
+		if len(fields) < 6 {
+			return
+		}
+		idx, err := strconv.ParseInt(fields[1], 16, 32)
+		if err != nil {
+			return
+		}
+		if iface, ok := ifindexMap[int(idx)]; ok {
+			counts[iface]++
+		}
+	})
+	if err != nil {
+		c.logger.Warn("cannot read IPv6 addresses", "path", path, "error", err)
+		return nil, false
+	}
+	return counts, true
+}
@@ -0,0 +1,37 @@
+package collector
+
+import "strings"
+
+// linkKind is the rtnetlink view of a link's type, used for devices whose
+// kind sysfs does not expose.
+type linkKind struct {
+	Kind       string // IFLA_INFO_KIND, e.g. "ipvlan", "veth", "bridge"
+	IPVLANMode string // "l2", "l3", "l3s" for ipvlan/ipvtap links, else ""
+}
+
+// isIPVLANKind reports whether an rtnetlink kind is an ipvlan (or its
+// tap flavour, ipvtap).
+func isIPVLANKind(kind string) bool {
+	return kind == "ipvlan" || kind == "ipvtap"
+}
+
+// isIPVLAN reports whether iface is an ipvlan/ipvtap device, using the
+// rtnetlink kind and falling back to the sysfs DEVTYPE and the conventional
+// "ipvl" name prefix when netlink is unavailable.
+func isIPVLAN(iface, devType string, kind linkKind) bool {
+	return isIPVLANKind(kind.Kind) || isIPVLANKind(devType) || strings.HasPrefix(iface, "ipvl")
+}
+
+// ipvlanModeName maps IFLA_IPVLAN_MODE values from <linux/if_link.h> to
+// their iproute2 names.
+func ipvlanModeName(mode uint16) string {
+	switch mode {
+	case 0:
+		return "l2"
+	case 1:
+		return "l3"
+	case 2:
+		return "l3s"
+	}
+	return "unknown"
+}
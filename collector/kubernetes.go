@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// buildContainerdMapping discovers containerd-managed Kubernetes pods (k3s,
+// kubeadm) by scanning /proc for processes in kubepods cgroups and maps their
+// host-side veth interfaces to pod names.
+//
+// Pod cgroup paths look like (systemd and cgroupfs drivers respectively):
+//
+//	0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod<uid>.slice/cri-containerd-<id>.scope
+//	0::/kubepods/besteffort/pod<uid>/<id>
+//
+// All containers of a pod share one network namespace, so only the first
+// process seen per pod is inspected. The pod name is taken from the pod's
+// hostname, which Kubernetes sets to the pod name.
+func (c *NetworkCollector) buildContainerdMapping(ifindexMap map[int]string) map[string]string {
+	result := make(map[string]string)
+
+	procDir := c.opts.ProcPath
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return result
+	}
+
+	seenPods := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 1 {
+			continue
+		}
+
+		cgroupData := readFileString(filepath.Join(procDir, entry.Name(), "cgroup"))
+		if cgroupData == "" {
+			continue
+		}
+		podUID, containerID := parseKubepodsCgroup(cgroupData)
+		if podUID == "" || seenPods[podUID] {
+			continue
+		}
+		seenPods[podUID] = true
+
+		podName := c.podName(pid, podUID, containerID)
+		for _, hostIfindex := range c.findContainerIflinks(procDir, pid) {
+			if hostIface, ok := ifindexMap[hostIfindex]; ok {
+				result[hostIface] = podName
+			}
+		}
+	}
+
+	if len(result) > 0 {
+		c.logger.Debug("mapped containerd/k8s pods", "count", len(result))
+	}
+
+	return result
+}
+
+// podName resolves a pod's name from the hostname inside its mount
+// namespace, falling back to the pod UID and then the container ID.
+func (c *NetworkCollector) podName(pid int, podUID, containerID string) string {
+	if name := readFileString(filepath.Join(c.opts.ProcPath, strconv.Itoa(pid), "root", "etc", "hostname")); name != "" {
+		return name
+	}
+	if podUID != "" {
+		return podUID
+	}
+	if len(containerID) > 12 {
+		return containerID[:12]
+	}
+	return containerID
+}
+
+// parseKubepodsCgroup extracts the pod UID and container ID from a cgroup
+// file content. Returns empty strings if the process is not in a kubepods
+// cgroup.
+func parseKubepodsCgroup(data string) (podUID, containerID string) {
+	for _, line := range strings.Split(data, "\n") {
+		idx := strings.Index(line, "kubepods")
+		if idx < 0 {
+			continue
+		}
+		for _, seg := range strings.Split(line[idx:], "/") {
+			seg = strings.TrimSuffix(seg, ".slice")
+			seg = strings.TrimSuffix(seg, ".scope")
+			switch {
+			case strings.HasPrefix(seg, "cri-containerd-"):
+				containerID = strings.TrimPrefix(seg, "cri-containerd-")
+			case strings.HasPrefix(seg, "pod"):
+				// cgroupfs: "pod<uid>"
+				podUID = strings.TrimPrefix(seg, "pod")
+			case strings.Contains(seg, "-pod"):
+				// systemd: "kubepods-<qos>-pod<uid_with_underscores>"
+				podUID = strings.ReplaceAll(seg[strings.LastIndex(seg, "-pod")+len("-pod"):], "_", "-")
+			case podUID != "" && seg != "":
+				// cgroupfs: container ID follows the pod segment.
+				containerID = seg
+			}
+		}
+		if podUID != "" {
+			return podUID, containerID
+		}
+	}
+	return "", ""
+}
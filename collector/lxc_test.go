@@ -0,0 +1,39 @@
+package collector
+
+import "testing"
+
+func TestParseLXCCgroup(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"v2 init.scope", "0::/lxc.payload.backupserver/init.scope\n", "backupserver"},
+		{"v2 payload root", "0::/lxc.payload.web\n", "web"},
+		{"v2 dotted name", "0::/lxc.payload.web.prod-1/init.scope\n", "web.prod-1"},
+		{"v2 under a parent slice", "0::/system.slice/lxc.payload.db/init.scope\n", "db"},
+		{
+			"v1 multi-line",
+			"12:pids:/lxc.payload.foo\n" +
+				"4:memory:/lxc.payload.foo\n" +
+				"1:name=systemd:/lxc.payload.foo/init.scope\n" +
+				"0::/lxc.payload.foo/init.scope\n",
+			"foo",
+		},
+		{"v1 named hierarchy only", "1:name=systemd:/lxc.payload.foo/init.scope\n", "foo"},
+		{"v1 comma-joined controllers", "3:cpu,cpuacct:/lxc.payload.media\n", "media"},
+		{"v1 skips lines outside the container", "5:devices:/\n4:memory:/lxc.payload.bar\n", "bar"},
+		{"nested service", "0::/lxc.payload.host1/system.slice/ssh.service\n", ""},
+		{"nested container", "0::/lxc.payload.host1/lxc.payload.inner/init.scope\n", ""},
+		{"nested container v1", "4:memory:/lxc.payload.host1/lxc.payload.inner\n1:name=systemd:/lxc.payload.host1/lxc.payload.inner/init.scope\n", ""},
+		{"monitor process", "0::/lxc.monitor.backupserver\n", ""},
+		{"host process", "0::/init.scope\n", ""},
+		{"empty payload name", "0::/lxc.payload./init.scope\n", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := parseLXCCgroup(tt.data); got != tt.want {
+			t.Errorf("%s: parseLXCCgroup(%q) = %q, want %q", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
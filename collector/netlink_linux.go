@@ -0,0 +1,200 @@
+//go:build linux
+
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+)
+
+// Link attribute types from <linux/if_link.h> not exported by syscall.
+const (
+	iflaStats64    = 23 // IFLA_STATS64
+	iflaLinkinfo   = 18 // IFLA_LINKINFO
+	iflaInfoKind   = 1  // IFLA_INFO_KIND
+	iflaInfoData   = 2  // IFLA_INFO_DATA
+	iflaIPVLANMode = 1  // IFLA_IPVLAN_MODE
+
+	nlaTypeMask = 0x3fff // NLA_TYPE_MASK: strips NLA_F_NESTED/NLA_F_NET_BYTEORDER
+)
+
+// rtnlLinkStats64Len is the size of the struct rtnl_link_stats64 prefix we
+// decode (23 __u64 fields up to and including tx_compressed).
+const rtnlLinkStats64Len = 23 * 8
+
+// readNetlinkStats dumps all links in the exporter's network namespace via
+// an RTM_GETLINK request and returns their 64-bit counters, mapped onto the
+// same fields /proc/net/dev reports.
+func readNetlinkStats() (map[string]interfaceStats, error) {
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("netlink RTM_GETLINK: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("netlink parse: %w", err)
+	}
+
+	result := make(map[string]interfaceStats)
+	for _, m := range msgs {
+		if m.Header.Type == syscall.NLMSG_DONE {
+			break
+		}
+		if m.Header.Type != syscall.RTM_NEWLINK {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		var stats []byte
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFLA_IFNAME:
+				name = string(trimNul(a.Value))
+			case iflaStats64:
+				stats = a.Value
+			}
+		}
+		if name == "" || len(stats) < rtnlLinkStats64Len {
+			continue
+		}
+		result[name] = decodeLinkStats64(stats)
+	}
+	return result, nil
+}
+
+// readLinkKinds dumps all links via RTM_GETLINK and returns the rtnetlink
+// kind (IFLA_INFO_KIND, e.g. "ipvlan", "veth", "bridge") of every link that
+// has one, plus the ipvlan mode where applicable.
+func readLinkKinds() (map[string]linkKind, error) {
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("netlink RTM_GETLINK: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("netlink parse: %w", err)
+	}
+
+	result := make(map[string]linkKind)
+	for _, m := range msgs {
+		if m.Header.Type == syscall.NLMSG_DONE {
+			break
+		}
+		if m.Header.Type != syscall.RTM_NEWLINK {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		var kind linkKind
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFLA_IFNAME:
+				name = string(trimNul(a.Value))
+			case iflaLinkinfo:
+				kind = decodeLinkInfo(a.Value)
+			}
+		}
+		if name != "" && kind.Kind != "" {
+			result[name] = kind
+		}
+	}
+	return result, nil
+}
+
+// decodeLinkInfo extracts the kind and, for ipvlan/ipvtap links, the mode
+// from a nested IFLA_LINKINFO attribute.
+func decodeLinkInfo(b []byte) linkKind {
+	var (
+		kind linkKind
+		data []byte
+	)
+	forEachRtattr(b, func(typ uint16, value []byte) {
+		switch typ {
+		case iflaInfoKind:
+			kind.Kind = string(trimNul(value))
+		case iflaInfoData:
+			data = value
+		}
+	})
+	if isIPVLANKind(kind.Kind) {
+		forEachRtattr(data, func(typ uint16, value []byte) {
+			if typ == iflaIPVLANMode && len(value) >= 2 {
+				kind.IPVLANMode = ipvlanModeName(binary.NativeEndian.Uint16(value))
+			}
+		})
+	}
+	return kind
+}
+
+// forEachRtattr calls fn for every attribute in a packed list of struct
+// rtattr, stopping at the first malformed header.
+func forEachRtattr(b []byte, fn func(typ uint16, value []byte)) {
+	for len(b) >= syscall.SizeofRtAttr {
+		l := int(binary.NativeEndian.Uint16(b[0:2]))
+		if l < syscall.SizeofRtAttr || l > len(b) {
+			return
+		}
+		fn(binary.NativeEndian.Uint16(b[2:4])&nlaTypeMask, b[syscall.SizeofRtAttr:l])
+		l = (l + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if l > len(b) {
+			return
+		}
+		b = b[l:]
+	}
+}
+
+// decodeLinkStats64 converts a struct rtnl_link_stats64 into interfaceStats,
+// aggregating error classes exactly as the kernel does for /proc/net/dev so
+// both backends produce identical series.
+func decodeLinkStats64(b []byte) interfaceStats {
+	f := func(i int) uint64 { return binary.NativeEndian.Uint64(b[i*8:]) }
+	var (
+		rxPackets, txPackets = f(0), f(1)
+		rxBytes, txBytes     = f(2), f(3)
+		rxErrors, txErrors   = f(4), f(5)
+		rxDropped, txDropped = f(6), f(7)
+		multicast, colls     = f(8), f(9)
+		rxLength, rxOver     = f(10), f(11)
+		rxCRC, rxFrame       = f(12), f(13)
+		rxFifo, rxMissed     = f(14), f(15)
+		txAborted, txCarrier = f(16), f(17)
+		txFifo, txHeartbeat  = f(18), f(19)
+		txWindow             = f(20)
+		rxCompr, txCompr     = f(21), f(22)
+	)
+	return interfaceStats{
+		RxBytes:      rxBytes,
+		RxPackets:    rxPackets,
+		RxErrors:     rxErrors,
+		RxDropped:    rxDropped + rxMissed,
+		RxFifo:       rxFifo,
+		RxFrame:      rxLength + rxOver + rxCRC + rxFrame,
+		RxCompressed: rxCompr,
+		RxMulticast:  multicast,
+		TxBytes:      txBytes,
+		TxPackets:    txPackets,
+		TxErrors:     txErrors,
+		TxDropped:    txDropped,
+		TxFifo:       txFifo,
+		TxColls:      colls,
+		TxCarrier:    txCarrier + txAborted + txWindow + txHeartbeat,
+		TxCompressed: txCompr,
+	}
+}
+
+// trimNul strips the trailing NUL terminator from a netlink string attribute.
+func trimNul(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
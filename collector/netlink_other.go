@@ -0,0 +1,15 @@
+//go:build !linux
+
+package collector
+
+import "errors"
+
+// readNetlinkStats is only implemented on Linux.
+func readNetlinkStats() (map[string]interfaceStats, error) {
+	return nil, errors.New("netlink stats backend is only supported on Linux")
+}
+
+// readLinkKinds is only implemented on Linux.
+func readLinkKinds() (map[string]linkKind, error) {
+	return nil, errors.New("netlink link kinds are only supported on Linux")
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// defaultNetns is the netns label value of the host's own interfaces.
+const defaultNetns = "default"
+
+// netnsKey returns the stats/info map key for iface in the named network
+// namespace ns. Interface names cannot contain '/', so keys never collide
+// with host interfaces.
+func netnsKey(ns, iface string) string {
+	return ns + "/" + iface
+}
+
+// splitNetnsStats separates host interfaces from those read out of named
+// network namespaces, returning the host subset and the namespaced keys.
+func splitNetnsStats(all map[string]interfaceStats) (map[string]interfaceStats, []string) {
+	host := make(map[string]interfaceStats, len(all))
+	var nsKeys []string
+	for key, s := range all {
+		if strings.Contains(key, "/") {
+			nsKeys = append(nsKeys, key)
+			continue
+		}
+		host[key] = s
+	}
+	return host, nsKeys
+}
+
+// readNetnsStats adds the counters of every configured named network
+// namespace to stats, keyed by netnsKey. Unreadable namespaces are logged
+// and skipped.
+func (c *NetworkCollector) readNetnsStats(stats map[string]interfaceStats) {
+	for _, ns := range c.opts.Netns {
+		path := filepath.Join(c.opts.RootfsPath, "run", "netns", ns)
+		nsStats, err := readNetnsStats(path)
+		if err != nil {
+			c.logger.Warn("cannot read network namespace counters", "netns", ns, "path", path, "error", err)
+			c.recordError("netns")
+			continue
+		}
+		for iface, s := range nsStats {
+			if c.interfaceAllowed(iface) {
+				stats[netnsKey(ns, iface)] = s
+			}
+		}
+	}
+}
+
+// netnsVLANMaps reads the VLAN config of each named network namespace
+// that appears in nsKeys, keyed by namespace. Each namespace has its own
+// /proc/net/vlan/config, which is only visible from inside it.
+func (c *NetworkCollector) netnsVLANMaps(nsKeys []string) map[string]map[string]vlanInfo {
+	result := make(map[string]map[string]vlanInfo)
+	if c.opts.DisableVLAN {
+		return result
+	}
+	for _, key := range nsKeys {
+		ns, _, _ := strings.Cut(key, "/")
+		if _, done := result[ns]; done {
+			continue
+		}
+		var vlans map[string]vlanInfo
+		path := filepath.Join(c.opts.RootfsPath, "run", "netns", ns)
+		err := inNetns(path, func() error {
+			vlans = c.buildVLANMap("/proc/thread-self/net/vlan/config")
+			return nil
+		})
+		if err != nil {
+			c.logger.Warn("cannot read network namespace VLAN config", "netns", ns, "path", path, "error", err)
+			c.recordError("vlan")
+		}
+		result[ns] = vlans
+	}
+	return result
+}
+
+// netnsInterfaceInfo returns the metadata for an interface in a named
+// network namespace. Sysfs and the container runtimes only describe the
+// host namespace, so classification is by name and the namespace's own
+// VLAN config (vlans, from netnsVLANMaps) alone.
+func netnsInterfaceInfo(key string, vlans map[string]map[string]vlanInfo) interfaceInfo {
+	ns, iface, _ := strings.Cut(key, "/")
+	info := interfaceInfo{
+		Name:           iface,
+		Instance:       iface,
+		InstanceType:   "unknown",
+		App:            "system",
+		State:          "unknown",
+		Duplex:         "unknown",
+		Netns:          ns,
+		Source:         StatsBackendProcfs,
+		TxQueueLen:     -1,
+		WireGuardPeers: -1,
+		TailscalePeers: -1,
+	}
+	switch {
+	case iface == "lo":
+		info.InstanceType = "loopback"
+		info.Instance = "loopback"
+	case isWireGuard(iface, ""):
+		info.InstanceType = "wireguard"
+	case isTailscale(iface):
+		info.InstanceType = "tailscale"
+	case isZeroTier(iface):
+		info.InstanceType = "zerotier"
+	}
+	if vi, ok := vlans[ns][iface]; ok {
+		info.InstanceType = "vlan"
+		info.VLAN = vi.ID
+		info.Parent = vi.Parent
+	}
+	return info
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// readNetnsStats reads the interface counters of the network namespace
+// bound at nsPath (e.g. /run/netns/vpn). Requires CAP_SYS_ADMIN.
+func readNetnsStats(nsPath string) (map[string]interfaceStats, error) {
+	var stats map[string]interfaceStats
+	err := inNetns(nsPath, func() error {
+		var err error
+		stats, err = readThreadNetDev()
+		return err
+	})
+	return stats, err
+}
+
+// inNetns runs fn with the calling goroutine's OS thread joined to the
+// network namespace bound at nsPath. The thread enters the namespace with
+// setns(2) and returns to its original namespace afterwards; inside fn,
+// /proc/thread-self/net describes the target namespace.
+func inNetns(nsPath string, fn func() error) error {
+	target, err := os.Open(nsPath)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	runtime.LockOSThread()
+
+	self, err := os.Open("/proc/thread-self/ns/net")
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer self.Close()
+
+	if err := setns(target.Fd()); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("setns %s: %w", nsPath, err)
+	}
+
+	fnErr := fn()
+
+	if err := setns(self.Fd()); err != nil {
+		// Leave the thread locked: the runtime terminates it when this
+		// goroutine exits instead of reusing it in the wrong namespace.
+		return fmt.Errorf("restore network namespace: %w", err)
+	}
+	runtime.UnlockOSThread()
+	return fnErr
+}
+
+// readThreadNetDev parses /proc/net/dev as seen by the current thread.
+func readThreadNetDev() (map[string]interfaceStats, error) {
+	f, err := os.Open("/proc/thread-self/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseProcNetDev(f)
+}
+
+func setns(fd uintptr) error {
+	return unix.Setns(int(fd), unix.CLONE_NEWNET)
+}
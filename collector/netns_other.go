@@ -0,0 +1,17 @@
+//go:build !linux
+
+package collector
+
+import "errors"
+
+var errNetnsUnsupported = errors.New("network namespaces are only supported on Linux")
+
+// readNetnsStats is only implemented on Linux.
+func readNetnsStats(nsPath string) (map[string]interfaceStats, error) {
+	return nil, errNetnsUnsupported
+}
+
+// inNetns is only implemented on Linux.
+func inNetns(nsPath string, fn func() error) error {
+	return errNetnsUnsupported
+}
@@ -3,14 +3,24 @@ package collector
 import (
 	"bufio"
 	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -28,130 +38,1087 @@ type NetworkCollector struct {
 	rxDropped *prometheus.Desc
 	txDropped *prometheus.Desc
 
-	opts         Options
-	dockerSocket string
-	logger       *slog.Logger
+	rxFifo       *prometheus.Desc
+	rxFrame      *prometheus.Desc
+	rxCompressed *prometheus.Desc
+	rxMulticast  *prometheus.Desc
+	txFifo       *prometheus.Desc
+	txColls      *prometheus.Desc
+	txCarrier    *prometheus.Desc
+	txCompressed *prometheus.Desc
+
+	// counters are the per-interface counters selected by Options.Counters.
+	counters []interfaceCounter
+
+	// rxBytesCurrent and txBytesCurrent repeat the byte counters as gauges
+	// when Options.GaugeSnapshot is set.
+	rxBytesCurrent *prometheus.Desc
+	txBytesCurrent *prometheus.Desc
+
+	speed       *prometheus.Desc
+	utilization *prometheus.Desc
+	mtu         *prometheus.Desc
+	txQueueLen  *prometheus.Desc
+	up          *prometheus.Desc
+
+	present *prometheus.Desc
+
+	carrierChanges *prometheus.Desc
+	duplexInfo     *prometheus.Desc
+	ipvlanInfo     *prometheus.Desc
+	queueCount     *prometheus.Desc
+	ipv6Addresses  *prometheus.Desc
+
+	wireGuardPeers  *prometheus.Desc
+	tailscalePeers  *prometheus.Desc
+	zeroTierNetwork *prometheus.Desc
+	dockerNetwork   *prometheus.Desc
+	hostNetInfo     *prometheus.Desc
+	interfaceCount  *prometheus.Desc
+	fdbEntries      *prometheus.Desc
+	restartCount    *prometheus.Desc
+	containerMAC    *prometheus.Desc
+	bondSlaveActive *prometheus.Desc
+	bondSlaveLinkUp *prometheus.Desc
+
+	// Per-container totals (Options.ContainerTotals).
+	containerRxBytes   *prometheus.Desc
+	containerTxBytes   *prometheus.Desc
+	containerRxPackets *prometheus.Desc
+	containerTxPackets *prometheus.Desc
+
+	// Per-VLAN totals (Options.AggregateVLANs).
+	vlanRxBytes *prometheus.Desc
+	vlanTxBytes *prometheus.Desc
+
+	snapshotAge    *prometheus.Desc
+	enrichmentAge  *prometheus.Desc
+	scrapeDuration *prometheus.Desc
+	cacheHits      *prometheus.Desc
+	cacheMisses    *prometheus.Desc
+	dockerConns    *prometheus.Desc
+	dockerUp       *prometheus.Desc
+	procfsSource   *prometheus.Desc
+
+	// scrapeErrors counts enrichment/collection failures by subsystem.
+	scrapeErrors *prometheus.CounterVec
+
+	// phaseDuration observes how long each collection phase takes.
+	phaseDuration *prometheus.HistogramVec
+
+	// dockerRequests and dockerRequestDuration instrument every Docker /
+	// Podman API request.
+	dockerRequests        *prometheus.CounterVec
+	dockerRequestDuration *prometheus.HistogramVec
+
+	// ctx bounds the collector's lifetime; cancelling it aborts in-flight
+	// commands and container runtime requests.
+	ctx      context.Context
+	opts     Options
+	runtimes []containerRuntime
+	incus    *IncusClient // nil unless Options.IncusSocket is set
+	// containerd is the fallback when no runtime in runtimes answers; nil
+	// unless Options.ContainerdSocket is set.
+	containerd *ContainerdClient
+	logger     *slog.Logger
+
+	// procFallbackWarned is set while counters come from the /proc/net/dev
+	// fallback, so the warning is logged once per transition.
+	procFallbackWarned atomic.Bool
+
+	ifaceFilter interfaceFilter
+
+	// typeInclude is the set of instance types to emit (nil = all).
+	typeInclude map[string]bool
+
+	// classifyRules override the built-in instance_type heuristics; the
+	// first matching rule wins.
+	classifyRules []classifyRule
+
+	// appInclude is the set of container apps reported individually; other
+	// container interfaces are collapsed into app="other" (nil = all).
+	appInclude map[string]bool
+
+	// netnsLabel is set when extra network namespaces are collected and
+	// every series carries a netns label.
+	netnsLabel bool
+
+	// topoCache holds the sysfs topology reused across scrapes.
+	topoCache topologyCache
+
+	// identity tracks each interface name's ifindex across scrapes.
+	identity identityTracker
+
+	// presence holds the newest snapshot's interfaces for net_interface_present.
+	presence presenceTracker
+
+	// util holds the previous scrape's byte counters for
+	// net_interface_utilization_ratio.
+	util utilizationTracker
+
+	// failures rate-limits warnings for enrichment failures that repeat
+	// on every scrape.
+	failures failureLog
+
+	// hostNet maps the name of every container running in the host network
+	// namespace to its app, as of the last Docker/Podman query.
+	hostNetMu sync.Mutex
+	hostNet   map[string]string
+
+	// snmp holds one client per Options.SNMPTargets entry.
+	snmp []*snmpClient
+
+	// snap holds the latest snapshot published by Run when background
+	// refresh is enabled.
+	snap atomic.Pointer[snapshot]
+
+	// gather shares on-demand collections between overlapping scrapes.
+	gather gatherGroup
+}
+
+// instanceTypes lists every InstanceType buildInterfaceInfo can assign.
+var instanceTypes = map[string]bool{
+	"physical": true, "bridge": true, "docker": true, "podman": true, "containerd": true,
+	"incus": true, "k8s": true, "vm": true, "vlan": true, "macvtap": true, "ipvlan": true, "sriov_vf": true, "vpn": true,
+	"bond": true, "wireguard": true, "tailscale": true, "zerotier": true, "loopback": true, "unknown": true,
 }
 
 // interfaceInfo contains resolved metadata for one network interface.
 type interfaceInfo struct {
 	Name         string
 	Instance     string // resolved name (container name, VM name, or iface name)
-	InstanceType string // "physical", "bridge", "docker", "vm", "vlan", "macvtap", "loopback", "unknown"
+	InstanceType string // "physical", "bridge", "docker", "podman", "containerd", "incus", "k8s", "vm", "vlan", "macvtap", "ipvlan", "sriov_vf", "vpn", "bond", "wireguard", "tailscale", "zerotier", "loopback", "unknown"
 	App          string // application name (Docker Compose project)
 	Bridge       string // parent bridge, if any
+	Role         string // "bridge" for a bridge, "uplink" or "member" for its ports, "" otherwise
+	Bond         string // parent bond, if this interface is an enslaved NIC
+	Parent       string // lower device of a stacked interface (macvlan/macvtap/ipvlan/VLAN), or an SR-IOV VF's PF
 	VLAN         string // 802.1Q VLAN ID (inherited from bridge uplink if applicable)
 	State        string // "up", "down", "unknown"
+	SpeedMbps    int64  // negotiated link speed in Mbit/s (0 = unknown/not applicable)
+	MTU          int64  // MTU in bytes (0 = unreadable)
+	TxQueueLen   int64  // tx_queue_len in packets (-1 = unreadable)
+
+	// CarrierChanges counts link up/down transitions; only set when
+	// HasCarrierChanges is true (most virtual devices lack the attribute).
+	CarrierChanges    uint64
+	HasCarrierChanges bool
+
+	Duplex    string // "full", "half", "unknown"
+	MAC       string // hardware address from sysfs (lowercase, colon-separated)
+	IP        string // container IP on the network owning this veth (container interfaces only)
+	Service   string // Docker Compose service of the owning container (container interfaces only)
+	Driver    string // kernel driver from device/driver in sysfs (physical interfaces and SR-IOV VFs only)
+	NetDriver string // Docker/Podman network driver of DockerNetwork (bridges and macvlan/ipvlan parents only)
+	Uplink    string // physical NIC(s) behind this interface's bridge, or behind itself if it is a bridge
+	Netns     string // network namespace name ("default" for the host) when Options.Netns is set
+	Source    string // counter source when Options.SNMPTargets is set; empty means Options.StatsBackend
+
+	// IPVLANMode is "l2", "l3" or "l3s" for ipvlan interfaces whose mode
+	// could be read via netlink.
+	IPVLANMode string
+
+	// BondSlave is the slave status when Bond is set.
+	BondSlave bondSlaveState
+
+	// WireGuardPeers is the number of configured peers for wireguard
+	// interfaces (-1 = wg unavailable; unused for other types).
+	WireGuardPeers int
+
+	// TailscalePeers is the number of tailnet peers for tailscale
+	// interfaces (-1 = tailscale CLI unavailable; unused for other types).
+	TailscalePeers int
+
+	// ZeroTierNetworkID and ZeroTierNetworkName identify the ZeroTier
+	// network a zerotier interface is joined to (empty when zerotier-cli is
+	// unavailable).
+	ZeroTierNetworkID   string
+	ZeroTierNetworkName string
+
+	// Collapsed marks a container interface outside Options.AppInclude; its
+	// counters are summed into a synthetic app="other" series.
+	Collapsed bool
+
+	// FDBEntries is the bridge's forwarding database size; only set when
+	// HasFDB is true (Linux bridges).
+	FDBEntries int
+	HasFDB     bool
+
+	// RestartCount is the Docker restart count of the owning container;
+	// only set when HasRestartCount is true (resolved docker veths).
+	RestartCount    int
+	HasRestartCount bool
+
+	// ContainerMACs maps each network of the owning container to the MAC
+	// the runtime assigned on it (resolved Docker/Podman veths only). This
+	// is the container-side address, not the host veth's.
+	ContainerMACs map[string]string
+
+	// DockerNetwork is the container network backed by this bridge, or
+	// riding on this interface as a macvlan/ipvlan parent, if any.
+	DockerNetwork *DockerNetworkInfo
+
+	// Queues is the rx/tx queue count of physical NICs; only set when
+	// HasQueues is true.
+	Queues    interfaceQueues
+	HasQueues bool
+
+	// IPv6Addresses is the number of IPv6 addresses on the interface; only
+	// set when HasIPv6 is true (if_inet6 readable).
+	IPv6Addresses int
+	HasIPv6       bool
 }
 
 // interfaceStats holds counters parsed from /proc/net/dev.
 type interfaceStats struct {
-	RxBytes   uint64
-	RxPackets uint64
-	RxErrors  uint64
-	RxDropped uint64
-	TxBytes   uint64
-	TxPackets uint64
-	TxErrors  uint64
-	TxDropped uint64
+	RxBytes      uint64
+	RxPackets    uint64
+	RxErrors     uint64
+	RxDropped    uint64
+	RxFifo       uint64
+	RxFrame      uint64
+	RxCompressed uint64
+	RxMulticast  uint64
+	TxBytes      uint64
+	TxPackets    uint64
+	TxErrors     uint64
+	TxDropped    uint64
+	TxFifo       uint64
+	TxColls      uint64
+	TxCarrier    uint64
+	TxCompressed uint64
 }
 
+// metricNamespaceRE matches a legal Prometheus metric-name prefix.
+var metricNamespaceRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 // NewNetworkCollector returns a collector that exposes per-interface network
-// traffic metrics with container/instance enrichment labels.
-func NewNetworkCollector(logger *slog.Logger, opts Options, dockerSocket string) *NetworkCollector {
-	labels := []string{"interface", "instance", "instance_type", "app", "bridge", "vlan", "state"}
-
-	return &NetworkCollector{
-		opts:         opts,
-		dockerSocket: dockerSocket,
-		logger:       logger,
+// traffic metrics with container/instance enrichment labels. Cancelling ctx
+// (e.g. on shutdown) aborts any exec or Docker API call still in flight.
+// Each of dockerSockets is queried as a separate Docker daemon.
+func NewNetworkCollector(ctx context.Context, logger *slog.Logger, opts Options, dockerSockets []string) (*NetworkCollector, error) {
+	labels := interfaceLabelNames(opts)
+	containerLabels := []string{"instance", "app", "instance_type"}
+	if opts.ContainerPerspective {
+		containerLabels = append(containerLabels, "perspective")
+	}
+
+	if opts.MetricNamespace != "" && !metricNamespaceRE.MatchString(opts.MetricNamespace) {
+		return nil, fmt.Errorf("invalid metric namespace %q: must match %s", opts.MetricNamespace, metricNamespaceRE)
+	}
+
+	switch opts.StatsBackend {
+	case "", StatsBackendProcfs, StatsBackendNetlink, StatsBackendSysfs:
+	default:
+		return nil, fmt.Errorf("unknown stats backend %q", opts.StatsBackend)
+	}
+
+	ifaceFilter, err := newInterfaceFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeInclude map[string]bool
+	if len(opts.InstanceTypeInclude) > 0 {
+		typeInclude = make(map[string]bool, len(opts.InstanceTypeInclude))
+		for _, t := range opts.InstanceTypeInclude {
+			if !instanceTypes[t] {
+				return nil, fmt.Errorf("unknown instance type %q in instance type filter", t)
+			}
+			typeInclude[t] = true
+		}
+	}
+
+	var classifyRules []classifyRule
+	if opts.ClassifyRules != "" {
+		rules, err := loadClassifyRules(opts.ClassifyRules)
+		if err != nil {
+			return nil, fmt.Errorf("classify rules: %w", err)
+		}
+		classifyRules = rules
+	}
+
+	var appInclude map[string]bool
+	if len(opts.AppInclude) > 0 {
+		appInclude = make(map[string]bool, len(opts.AppInclude))
+		for _, app := range opts.AppInclude {
+			appInclude[app] = true
+		}
+	}
+
+	scrapeErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_scrape_errors_total"),
+		Help: "Total collection failures by subsystem.",
+	}, []string{"subsystem"})
+	scrapeErrors.WithLabelValues("procfs")
+	for sub, disabled := range map[string]bool{
+		"docker": opts.DisableDocker,
+		"incus":  opts.DisableIncus,
+		"vm":     opts.DisableVM,
+		"vlan":   opts.DisableVLAN,
+	} {
+		if !disabled {
+			scrapeErrors.WithLabelValues(sub)
+		}
+	}
+
+	dockerRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_docker_requests_total"),
+		Help: "Total Docker/Podman API requests by endpoint and HTTP status.",
+	}, []string{"endpoint", "status"})
+	dockerRequestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_docker_request_duration_seconds"),
+		Help:    "Latency of Docker/Podman API requests by endpoint.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"endpoint"})
+
+	dockerOpts := DockerClientOptions{
+		InspectCacheTTL:    opts.DockerCacheTTL,
+		InspectConcurrency: opts.DockerInspectConcurrency,
+		MaxRetries:         opts.DockerMaxRetries,
+		ObserveRequest: func(endpoint, status string, d time.Duration) {
+			dockerRequests.WithLabelValues(endpoint, status).Inc()
+			dockerRequestDuration.WithLabelValues(endpoint).Observe(d.Seconds())
+		},
+	}
+	dockerTLS, err := LoadDockerTLSConfig(opts.DockerTLSCert, opts.DockerTLSKey, opts.DockerTLSCA)
+	if err != nil {
+		return nil, err
+	}
+	remoteOpts := dockerOpts
+	remoteOpts.TLSConfig = dockerTLS
+	var runtimes []containerRuntime
+	if opts.DisableDocker {
+		dockerSockets = nil
+	}
+	for _, socket := range dockerSockets {
+		runtimes = append(runtimes, containerRuntime{name: "docker", endpoint: socket, client: NewDockerClient(socket, remoteOpts), up: new(atomic.Bool)})
+	}
+	if len(opts.Netns) > 0 {
+		scrapeErrors.WithLabelValues("netns")
+	}
+	var snmp []*snmpClient
+	for _, target := range slices.Compact(slices.Sorted(slices.Values(opts.SNMPTargets))) {
+		client, err := newSNMPClient(target, opts)
+		if err != nil {
+			return nil, fmt.Errorf("snmp target %s: %w", target, err)
+		}
+		snmp = append(snmp, client)
+	}
+	if len(snmp) > 0 {
+		scrapeErrors.WithLabelValues("snmp")
+	}
+	if opts.PodmanSocket != "" {
+		runtimes = append(runtimes, containerRuntime{name: "podman", endpoint: opts.PodmanSocket, client: NewDockerClient(opts.PodmanSocket, dockerOpts), up: new(atomic.Bool)})
+		scrapeErrors.WithLabelValues("podman")
+	}
+
+	phaseDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:                            prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_phase_duration_seconds"),
+		Help:                            "Time spent in each collection phase (counter read and enrichment sources).",
+		Buckets:                         prometheus.DefBuckets,
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"phase"})
+
+	var incus *IncusClient
+	if opts.IncusSocket != "" && !opts.DisableIncus {
+		incus = NewIncusClient(opts.IncusSocket)
+	}
+	var containerd *ContainerdClient
+	if opts.ContainerdSocket != "" && !opts.DisableDocker {
+		containerd = NewContainerdClient(opts.ContainerdSocket)
+		scrapeErrors.WithLabelValues("containerd")
+	}
+
+	c := &NetworkCollector{
+		ctx:                   ctx,
+		scrapeErrors:          scrapeErrors,
+		phaseDuration:         phaseDuration,
+		dockerRequests:        dockerRequests,
+		dockerRequestDuration: dockerRequestDuration,
+		ifaceFilter:           ifaceFilter,
+		typeInclude:           typeInclude,
+		classifyRules:         classifyRules,
+		appInclude:            appInclude,
+		netnsLabel:            len(opts.Netns) > 0,
+		opts:                  opts,
+		runtimes:              runtimes,
+		snmp:                  snmp,
+		incus:                 incus,
+		containerd:            containerd,
+		logger:                logger,
 		rxBytes: prometheus.NewDesc(
-			"net_interface_rx_bytes_total",
-			"Total bytes received on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_bytes_total"),
+			perspectiveHelp(opts, "Total bytes received on this interface."),
 			labels, nil,
 		),
 		txBytes: prometheus.NewDesc(
-			"net_interface_tx_bytes_total",
-			"Total bytes transmitted on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_bytes_total"),
+			perspectiveHelp(opts, "Total bytes transmitted on this interface."),
 			labels, nil,
 		),
 		rxPackets: prometheus.NewDesc(
-			"net_interface_rx_packets_total",
-			"Total packets received on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_packets_total"),
+			perspectiveHelp(opts, "Total packets received on this interface."),
 			labels, nil,
 		),
 		txPackets: prometheus.NewDesc(
-			"net_interface_tx_packets_total",
-			"Total packets transmitted on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_packets_total"),
+			perspectiveHelp(opts, "Total packets transmitted on this interface."),
 			labels, nil,
 		),
 		rxErrors: prometheus.NewDesc(
-			"net_interface_rx_errors_total",
-			"Total receive errors on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_errors_total"),
+			perspectiveHelp(opts, "Total receive errors on this interface."),
 			labels, nil,
 		),
 		txErrors: prometheus.NewDesc(
-			"net_interface_tx_errors_total",
-			"Total transmit errors on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_errors_total"),
+			perspectiveHelp(opts, "Total transmit errors on this interface."),
 			labels, nil,
 		),
 		rxDropped: prometheus.NewDesc(
-			"net_interface_rx_dropped_total",
-			"Total received packets dropped on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_dropped_total"),
+			perspectiveHelp(opts, "Total received packets dropped on this interface."),
 			labels, nil,
 		),
 		txDropped: prometheus.NewDesc(
-			"net_interface_tx_dropped_total",
-			"Total transmitted packets dropped on this interface.",
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_dropped_total"),
+			perspectiveHelp(opts, "Total transmitted packets dropped on this interface."),
+			labels, nil,
+		),
+		rxFifo: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_fifo_total"),
+			perspectiveHelp(opts, "Total receive FIFO buffer errors (overruns) on this interface."),
 			labels, nil,
 		),
+		rxFrame: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_frame_total"),
+			"Total receive framing errors on this interface.",
+			labels, nil,
+		),
+		rxCompressed: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_compressed_total"),
+			perspectiveHelp(opts, "Total compressed packets received on this interface."),
+			labels, nil,
+		),
+		rxMulticast: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_multicast_total"),
+			"Total multicast packets received on this interface.",
+			labels, nil,
+		),
+		txFifo: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_fifo_total"),
+			perspectiveHelp(opts, "Total transmit FIFO buffer errors on this interface."),
+			labels, nil,
+		),
+		txColls: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_collisions_total"),
+			"Total collisions detected while transmitting on this interface.",
+			labels, nil,
+		),
+		txCarrier: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_carrier_errors_total"),
+			"Total transmit carrier errors on this interface.",
+			labels, nil,
+		),
+		txCompressed: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_compressed_total"),
+			perspectiveHelp(opts, "Total compressed packets transmitted on this interface."),
+			labels, nil,
+		),
+		speed: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_speed_mbps"),
+			"Negotiated link speed of this interface in Mbit/s.",
+			labels, nil,
+		),
+		mtu: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_mtu_bytes"),
+			"Maximum transmission unit of this interface in bytes.",
+			labels, nil,
+		),
+		txQueueLen: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_queue_length"),
+			"Transmit queue length (tx_queue_len) of this interface in packets.",
+			labels, nil,
+		),
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_up"),
+			"Whether this interface's operstate is up (1) or not (0).",
+			labels, nil,
+		),
+		present: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_present"),
+			"Whether this interface exists (1); 0 is emitted for one scrape after it disappears.",
+			labels, nil,
+		),
+		carrierChanges: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_carrier_changes_total"),
+			"Total number of link carrier up/down transitions on this interface.",
+			labels, nil,
+		),
+		rxBytesCurrent: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_rx_bytes_current"),
+			perspectiveHelp(opts, "Bytes received on this interface as of this scrape, as a gauge for interfaces too short-lived for rate()."),
+			labels, nil,
+		),
+		txBytesCurrent: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_tx_bytes_current"),
+			perspectiveHelp(opts, "Bytes transmitted on this interface as of this scrape, as a gauge for interfaces too short-lived for rate()."),
+			labels, nil,
+		),
+		utilization: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_utilization_ratio"),
+			perspectiveHelp(opts, "Fraction of the negotiated link speed used per direction since the previous scrape."),
+			append(append([]string{}, labels...), "direction"), nil,
+		),
+		duplexInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_duplex_info"),
+			"Negotiated duplex mode of this interface (always 1).",
+			append(append([]string{}, labels...), "duplex"), nil,
+		),
+		ipvlanInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_ipvlan_info"),
+			"Operating mode of this ipvlan interface (always 1).",
+			append(append([]string{}, labels...), "mode"), nil,
+		),
+		queueCount: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_queue_count"),
+			"Number of rx/tx queues exposed by this physical interface in sysfs.",
+			append(append([]string{}, labels...), "direction"), nil,
+		),
+		ipv6Addresses: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_ipv6_address_count"),
+			"Number of IPv6 addresses assigned to this interface.",
+			labels, nil,
+		),
+		dockerNetwork: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_docker_network_info"),
+			"Docker/Podman network backed by this bridge (or macvlan/ipvlan parent interface), with its driver, IPAM subnet and gateway and whether it is internal or attachable (always 1).",
+			[]string{"network", "bridge", "driver", "subnet", "gateway", "internal", "attachable"}, nil,
+		),
+		interfaceCount: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_interface_count"),
+			"Number of interfaces of each instance type, before the instance type and app filters.",
+			[]string{"instance_type"}, nil,
+		),
+		hostNetInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_docker_host_network_container_info"),
+			"Container sharing the host network namespace (network_mode: host); its traffic is counted on host interfaces (always 1).",
+			[]string{"instance", "app"}, nil,
+		),
+		fdbEntries: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_bridge_fdb_entries"),
+			"Number of entries in this Linux bridge's forwarding database.",
+			[]string{"bridge"}, nil,
+		),
+		containerMAC: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_mac_info"),
+			"MAC address the container runtime assigned to this container on a network (always 1).",
+			[]string{"instance", "network", "mac"}, nil,
+		),
+		restartCount: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_restart_count"),
+			"Number of times Docker has restarted this container.",
+			[]string{"instance", "app"}, nil,
+		),
+		wireGuardPeers: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_wireguard_peers"),
+			"Number of peers configured on this WireGuard interface.",
+			[]string{"interface"}, nil,
+		),
+		tailscalePeers: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_tailscale_peers"),
+			"Number of tailnet peers visible through this Tailscale interface.",
+			[]string{"interface"}, nil,
+		),
+		zeroTierNetwork: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_zerotier_network_info"),
+			"ZeroTier network joined through this interface (always 1).",
+			[]string{"interface", "network_id", "name"}, nil,
+		),
+		bondSlaveActive: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_bond_slave_active"),
+			"Whether this bond slave is active (1) or a backup (0).",
+			[]string{"bond", "slave"}, nil,
+		),
+		bondSlaveLinkUp: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_bond_slave_link_up"),
+			"Whether this bond slave's MII link status is up (1) or down (0).",
+			[]string{"bond", "slave"}, nil,
+		),
+		containerRxBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_rx_bytes_total"),
+			perspectiveHelp(opts, "Total bytes received summed across all interfaces of this container."),
+			containerLabels, nil,
+		),
+		containerTxBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_tx_bytes_total"),
+			perspectiveHelp(opts, "Total bytes transmitted summed across all interfaces of this container."),
+			containerLabels, nil,
+		),
+		containerRxPackets: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_rx_packets_total"),
+			perspectiveHelp(opts, "Total packets received summed across all interfaces of this container."),
+			containerLabels, nil,
+		),
+		containerTxPackets: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_container_tx_packets_total"),
+			perspectiveHelp(opts, "Total packets transmitted summed across all interfaces of this container."),
+			containerLabels, nil,
+		),
+		vlanRxBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_vlan_rx_bytes_total"),
+			"Total bytes received summed across all interfaces carrying this VLAN.",
+			[]string{"vlan"}, nil,
+		),
+		vlanTxBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_vlan_tx_bytes_total"),
+			"Total bytes transmitted summed across all interfaces carrying this VLAN.",
+			[]string{"vlan"}, nil,
+		),
+		snapshotAge: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_snapshot_age_seconds"),
+			"Seconds since the interface counters being served were read.",
+			nil, nil,
+		),
+		enrichmentAge: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_enrichment_age_seconds"),
+			"Seconds since the interface enrichment labels being served were resolved.",
+			nil, nil,
+		),
+		scrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_scrape_duration_seconds"),
+			"Wall time spent gathering the interface counters and enrichment being served.",
+			nil, nil,
+		),
+		cacheHits: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_cache_hits_total"),
+			"Lookups served from an enrichment cache.",
+			[]string{"cache"}, nil,
+		),
+		cacheMisses: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_cache_misses_total"),
+			"Lookups that missed an enrichment cache and were fetched or rebuilt.",
+			[]string{"cache"}, nil,
+		),
+		dockerConns: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_docker_open_connections"),
+			"Connections currently open to a Docker/Podman API endpoint, idle keep-alive connections included.",
+			[]string{"runtime", "address"}, nil,
+		),
+		dockerUp: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_docker_up"),
+			"Whether a Docker/Podman API endpoint answered /version with 200 at the latest probe (1) or not (0).",
+			[]string{"runtime", "address"}, nil,
+		),
+		procfsSource: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_exporter_procfs_source_info"),
+			"Path the interface counters were read from (always 1).",
+			[]string{"path"}, nil,
+		),
+	}
+	if c.counters, err = c.selectCounters(opts.Counters); err != nil {
+		return nil, err
 	}
+	return c, nil
 }
 
 // Describe implements prometheus.Collector.
 func (c *NetworkCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- c.rxBytes
-	ch <- c.txBytes
-	ch <- c.rxPackets
-	ch <- c.txPackets
-	ch <- c.rxErrors
-	ch <- c.txErrors
-	ch <- c.rxDropped
-	ch <- c.txDropped
+	for _, ctr := range c.counters {
+		ch <- ctr.desc
+	}
+	if c.opts.GaugeSnapshot {
+		ch <- c.rxBytesCurrent
+		ch <- c.txBytesCurrent
+	}
+	ch <- c.speed
+	if c.opts.Utilization {
+		ch <- c.utilization
+	}
+	ch <- c.mtu
+	ch <- c.carrierChanges
+	ch <- c.txQueueLen
+	ch <- c.up
+	ch <- c.present
+	ch <- c.duplexInfo
+	ch <- c.ipvlanInfo
+	ch <- c.queueCount
+	ch <- c.ipv6Addresses
+	ch <- c.wireGuardPeers
+	ch <- c.tailscalePeers
+	ch <- c.zeroTierNetwork
+	ch <- c.dockerNetwork
+	ch <- c.hostNetInfo
+	ch <- c.interfaceCount
+	ch <- c.fdbEntries
+	ch <- c.restartCount
+	ch <- c.containerMAC
+	ch <- c.bondSlaveActive
+	ch <- c.bondSlaveLinkUp
+	if c.opts.ContainerTotals {
+		ch <- c.containerRxBytes
+		ch <- c.containerTxBytes
+		ch <- c.containerRxPackets
+		ch <- c.containerTxPackets
+	}
+	if c.opts.AggregateVLANs {
+		ch <- c.vlanRxBytes
+		ch <- c.vlanTxBytes
+	}
+	ch <- c.snapshotAge
+	ch <- c.enrichmentAge
+	ch <- c.scrapeDuration
+	ch <- c.cacheHits
+	ch <- c.cacheMisses
+	ch <- c.dockerConns
+	ch <- c.dockerUp
+	ch <- c.procfsSource
+	c.scrapeErrors.Describe(ch)
+	c.phaseDuration.Describe(ch)
+	c.dockerRequests.Describe(ch)
+	c.dockerRequestDuration.Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
 func (c *NetworkCollector) Collect(ch chan<- prometheus.Metric) {
-	// 1. Read interface stats from /proc/1/net/dev (host network namespace).
-	stats, err := c.readProcNetDev()
-	if err != nil {
-		c.logger.Error("failed to read /proc/1/net/dev", "error", err)
+	// 1. Get counters + interface metadata, either from the background
+	// snapshot or gathered on demand.
+	snap := c.currentSnapshot()
+	defer c.scrapeErrors.Collect(ch)
+	defer c.phaseDuration.Collect(ch)
+	defer c.dockerRequests.Collect(ch)
+	defer c.dockerRequestDuration.Collect(ch)
+	if snap == nil {
 		return
 	}
 
-	c.logger.Debug("collected interface stats", "count", len(stats))
+	c.logger.Debug("collected interface stats", "count", len(snap.stats))
 
-	// 2. Build interface → metadata mapping.
-	infoMap := c.buildInterfaceInfo(stats)
-
-	// 3. Emit metrics.
-	for iface, s := range stats {
-		info, ok := infoMap[iface]
+	// 2. Emit metrics.
+	present := make(map[string][]string, len(snap.stats))
+	other := make(map[string]interfaceStats) // instance_type → summed counters of collapsed interfaces
+	restarts := make(map[string]bool)        // containers whose restart count was emitted
+	macs := make(map[string]bool)            // containers whose runtime MACs were emitted
+	for iface, s := range snap.stats {
+		info, ok := snap.info[iface]
 		if !ok {
 			continue
 		}
 
-		labels := []string{info.Name, info.Instance, info.InstanceType, info.App, info.Bridge, info.VLAN, info.State}
+		if !c.instanceTypeAllowed(info.InstanceType) {
+			continue
+		}
+		if c.containerPerspective(info) {
+			s = s.swapped()
+		}
+		// Stale down interfaces that never carried traffic only add noise.
+		if c.opts.SkipZeroDown && info.State == "down" && s.RxBytes == 0 && s.TxBytes == 0 {
+			continue
+		}
+		if info.Collapsed {
+			t := other[info.InstanceType]
+			t.add(s)
+			other[info.InstanceType] = t
+			continue
+		}
+
+		labels := c.interfaceLabelValues(info)
+		present[iface] = labels
+
+		c.emitCounters(ch, s, labels)
+
+		if info.SpeedMbps > 0 {
+			ch <- prometheus.MustNewConstMetric(c.speed, prometheus.GaugeValue, float64(info.SpeedMbps), labels...)
+			if c.opts.Utilization {
+				if rx, tx, ok := c.linkUtilization(iface, s, snap.countersAt, info.SpeedMbps); ok {
+					ch <- prometheus.MustNewConstMetric(c.utilization, prometheus.GaugeValue, rx, append(labels[:len(labels):len(labels)], "rx")...)
+					ch <- prometheus.MustNewConstMetric(c.utilization, prometheus.GaugeValue, tx, append(labels[:len(labels):len(labels)], "tx")...)
+				}
+			}
+		}
+		if info.MTU > 0 {
+			ch <- prometheus.MustNewConstMetric(c.mtu, prometheus.GaugeValue, float64(info.MTU), labels...)
+		}
+		if info.TxQueueLen >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.txQueueLen, prometheus.GaugeValue, float64(info.TxQueueLen), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, boolToFloat(info.State == "up"), labels...)
+		if info.HasCarrierChanges {
+			ch <- prometheus.MustNewConstMetric(c.carrierChanges, prometheus.CounterValue, float64(info.CarrierChanges), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.duplexInfo, prometheus.GaugeValue, 1, append(labels[:len(labels):len(labels)], info.Duplex)...)
+		if info.IPVLANMode != "" {
+			ch <- prometheus.MustNewConstMetric(c.ipvlanInfo, prometheus.GaugeValue, 1, append(labels[:len(labels):len(labels)], info.IPVLANMode)...)
+		}
+		if info.HasIPv6 {
+			ch <- prometheus.MustNewConstMetric(c.ipv6Addresses, prometheus.GaugeValue, float64(info.IPv6Addresses), labels...)
+		}
+		if info.HasQueues {
+			ch <- prometheus.MustNewConstMetric(c.queueCount, prometheus.GaugeValue, float64(info.Queues.Rx), append(labels[:len(labels):len(labels)], "rx")...)
+			ch <- prometheus.MustNewConstMetric(c.queueCount, prometheus.GaugeValue, float64(info.Queues.Tx), append(labels[:len(labels):len(labels)], "tx")...)
+		}
+		if info.Bond != "" {
+			ch <- prometheus.MustNewConstMetric(c.bondSlaveActive, prometheus.GaugeValue, boolToFloat(info.BondSlave.Active), info.Bond, iface)
+			ch <- prometheus.MustNewConstMetric(c.bondSlaveLinkUp, prometheus.GaugeValue, boolToFloat(info.BondSlave.LinkUp), info.Bond, iface)
+		}
+		if n := info.DockerNetwork; n != nil {
+			c.emitDockerNetworkInfo(ch, iface, n)
+		}
+		if info.HasFDB {
+			ch <- prometheus.MustNewConstMetric(c.fdbEntries, prometheus.GaugeValue, float64(info.FDBEntries), iface)
+		}
+		// A container with several veths still gets a single series.
+		if info.HasRestartCount && !restarts[info.Instance] {
+			restarts[info.Instance] = true
+			ch <- prometheus.MustNewConstMetric(c.restartCount, prometheus.GaugeValue, float64(info.RestartCount), info.Instance, info.App)
+		}
+		if len(info.ContainerMACs) > 0 && !macs[info.Instance] {
+			macs[info.Instance] = true
+			for network, mac := range info.ContainerMACs {
+				ch <- prometheus.MustNewConstMetric(c.containerMAC, prometheus.GaugeValue, 1, info.Instance, network, mac)
+			}
+		}
+		if info.InstanceType == "wireguard" && info.WireGuardPeers >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.wireGuardPeers, prometheus.GaugeValue, float64(info.WireGuardPeers), iface)
+		}
+		if info.InstanceType == "tailscale" && info.TailscalePeers >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.tailscalePeers, prometheus.GaugeValue, float64(info.TailscalePeers), iface)
+		}
+		if info.InstanceType == "zerotier" && info.ZeroTierNetworkID != "" {
+			ch <- prometheus.MustNewConstMetric(c.zeroTierNetwork, prometheus.GaugeValue, 1, iface, info.ZeroTierNetworkID, info.ZeroTierNetworkName)
+		}
+	}
+
+	for instanceType, s := range other {
+		c.emitCounters(ch, s, c.interfaceLabelValues(otherInterfaceInfo(instanceType)))
+	}
+	if len(c.snmp) > 0 {
+		c.emitSNMP(ch, snap)
+	}
+	c.emitPresence(ch, snap, present)
+	if c.opts.Utilization {
+		c.pruneUtilization(present)
+	}
+
+	c.emitInterfaceCounts(ch, snap)
+	if c.opts.ContainerTotals {
+		c.emitContainerTotals(ch, snap)
+	}
+	if c.opts.AggregateVLANs {
+		c.emitVLANTotals(ch, snap)
+	}
+
+	now := time.Now()
+	ch <- prometheus.MustNewConstMetric(c.snapshotAge, prometheus.GaugeValue, now.Sub(snap.countersAt).Seconds())
+	ch <- prometheus.MustNewConstMetric(c.enrichmentAge, prometheus.GaugeValue, now.Sub(snap.enrichedAt).Seconds())
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, snap.duration.Seconds())
+	c.emitCacheStats(ch)
+	c.hostNetMu.Lock()
+	for name, app := range c.hostNet {
+		ch <- prometheus.MustNewConstMetric(c.hostNetInfo, prometheus.GaugeValue, 1, name, app)
+	}
+	c.hostNetMu.Unlock()
+	for _, rt := range c.runtimes {
+		ch <- prometheus.MustNewConstMetric(c.dockerConns, prometheus.GaugeValue, float64(rt.client.OpenConnections()), rt.name, rt.endpoint)
+		ch <- prometheus.MustNewConstMetric(c.dockerUp, prometheus.GaugeValue, boolToFloat(rt.up.Load()), rt.name, rt.endpoint)
+	}
+	ch <- prometheus.MustNewConstMetric(c.procfsSource, prometheus.GaugeValue, 1, snap.source)
+}
+
+// emitCounters emits the selected /proc/net/dev counters of one interface.
+func (c *NetworkCollector) emitCounters(ch chan<- prometheus.Metric, s interfaceStats, labels []string) {
+	for _, ctr := range c.counters {
+		ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.CounterValue, float64(ctr.value(s)), labels...)
+	}
+	if c.opts.GaugeSnapshot {
+		ch <- prometheus.MustNewConstMetric(c.rxBytesCurrent, prometheus.GaugeValue, float64(s.RxBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.txBytesCurrent, prometheus.GaugeValue, float64(s.TxBytes), labels...)
+	}
+}
+
+// emitDockerNetworkInfo emits one net_docker_network_info series per IPAM
+// pool of the network backing bridge (a single series with empty subnet and
+// gateway when the network has no IPAM config).
+func (c *NetworkCollector) emitDockerNetworkInfo(ch chan<- prometheus.Metric, bridge string, n *DockerNetworkInfo) {
+	internal, attachable := strconv.FormatBool(n.Internal), strconv.FormatBool(n.Attachable)
+	if len(n.IPAM) == 0 {
+		ch <- prometheus.MustNewConstMetric(c.dockerNetwork, prometheus.GaugeValue, 1, n.Name, bridge, n.Driver, "", "", internal, attachable)
+		return
+	}
+	for _, cfg := range n.IPAM {
+		ch <- prometheus.MustNewConstMetric(c.dockerNetwork, prometheus.GaugeValue, 1, n.Name, bridge, n.Driver, cfg.Subnet, cfg.Gateway, internal, attachable)
+	}
+}
+
+// interfaceLabelNames returns the label names attached to every
+// per-interface series, including optional labels enabled in opts.
+func interfaceLabelNames(opts Options) []string {
+	labels := []string{"interface", "instance", "instance_type", "app", "bridge", "bond", "parent", "vlan", "state", "role"}
+	if opts.MACLabel {
+		labels = append(labels, "mac")
+	}
+	if opts.IPLabel {
+		labels = append(labels, "ip")
+	}
+	if opts.ServiceLabel {
+		labels = append(labels, "service")
+	}
+	if opts.DriverLabel {
+		labels = append(labels, "driver")
+	}
+	if opts.NetDriverLabel {
+		labels = append(labels, "net_driver")
+	}
+	if opts.UplinkLabel {
+		labels = append(labels, "uplink")
+	}
+	if len(opts.Netns) > 0 {
+		labels = append(labels, "netns")
+	}
+	if opts.ContainerPerspective {
+		labels = append(labels, "perspective")
+	}
+	if len(opts.SNMPTargets) > 0 {
+		labels = append(labels, "source")
+	}
+	return labels
+}
+
+// interfaceLabelValues returns info's label values in interfaceLabelNames order.
+func (c *NetworkCollector) interfaceLabelValues(info interfaceInfo) []string {
+	values := []string{info.Name, info.Instance, info.InstanceType, info.App, info.Bridge, info.Bond, info.Parent, info.VLAN, info.State, info.Role}
+	if c.opts.MACLabel {
+		values = append(values, info.MAC)
+	}
+	if c.opts.IPLabel {
+		values = append(values, info.IP)
+	}
+	if c.opts.ServiceLabel {
+		values = append(values, info.Service)
+	}
+	if c.opts.DriverLabel {
+		values = append(values, info.Driver)
+	}
+	if c.opts.NetDriverLabel {
+		values = append(values, info.NetDriver)
+	}
+	if c.opts.UplinkLabel {
+		values = append(values, info.Uplink)
+	}
+	if c.netnsLabel {
+		values = append(values, info.Netns)
+	}
+	if c.opts.ContainerPerspective {
+		values = append(values, c.perspectiveLabel(info))
+	}
+	if len(c.snmp) > 0 {
+		source := info.Source
+		if source == "" {
+			source = cmp.Or(c.opts.StatsBackend, StatsBackendProcfs)
+		}
+		values = append(values, source)
+	}
+	return values
+}
+
+// observePhase records the time since start for a collection phase
+// ("procfs", "docker", "incus", "vm", "vlan", "snmp").
+func (c *NetworkCollector) observePhase(phase string, start time.Time) {
+	c.phaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+// recordError increments the scrape error counter for a subsystem
+// ("procfs", "docker", "incus", "vm", "vlan", "ovs", "snmp").
+func (c *NetworkCollector) recordError(subsystem string) {
+	c.scrapeErrors.WithLabelValues(subsystem).Inc()
+}
+
+// readStats reads the current interface counters and drops interfaces
+// rejected by the include/exclude filters, so filtered interfaces are never
+// enriched or emitted.
+func (c *NetworkCollector) readStats() (map[string]interfaceStats, string, error) {
+	var (
+		stats  map[string]interfaceStats
+		source string
+		err    error
+	)
+	start := time.Now()
+	switch c.opts.StatsBackend {
+	case StatsBackendNetlink:
+		stats, err = readNetlinkStats()
+		source = "netlink"
+	case StatsBackendSysfs:
+		source = c.sysClassNetPath()
+		stats, err = readSysfsStats(source)
+	default:
+		stats, source, err = c.readProcNetDev()
+	}
+	c.observePhase("procfs", start)
+	if err != nil {
+		return nil, "", err
+	}
+	for iface := range stats {
+		if !c.interfaceAllowed(iface) {
+			c.logger.Debug("interface filtered out", "interface", iface)
+			delete(stats, iface)
+		}
+	}
+	c.readNetnsStats(stats)
+	return stats, source, nil
+}
+
+// interfaceFilter holds the compiled interface name filters (nil = no
+// filter), shared by every collector that emits per-interface series.
+type interfaceFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
 
-		ch <- prometheus.MustNewConstMetric(c.rxBytes, prometheus.CounterValue, float64(s.RxBytes), labels...)
-		ch <- prometheus.MustNewConstMetric(c.txBytes, prometheus.CounterValue, float64(s.TxBytes), labels...)
-		ch <- prometheus.MustNewConstMetric(c.rxPackets, prometheus.CounterValue, float64(s.RxPackets), labels...)
-		ch <- prometheus.MustNewConstMetric(c.txPackets, prometheus.CounterValue, float64(s.TxPackets), labels...)
-		ch <- prometheus.MustNewConstMetric(c.rxErrors, prometheus.CounterValue, float64(s.RxErrors), labels...)
-		ch <- prometheus.MustNewConstMetric(c.txErrors, prometheus.CounterValue, float64(s.TxErrors), labels...)
-		ch <- prometheus.MustNewConstMetric(c.rxDropped, prometheus.CounterValue, float64(s.RxDropped), labels...)
-		ch <- prometheus.MustNewConstMetric(c.txDropped, prometheus.CounterValue, float64(s.TxDropped), labels...)
+// newInterfaceFilter compiles opts.InterfaceInclude and
+// opts.InterfaceExclude.
+func newInterfaceFilter(opts Options) (interfaceFilter, error) {
+	var f interfaceFilter
+	if opts.InterfaceInclude != "" {
+		re, err := regexp.Compile(opts.InterfaceInclude)
+		if err != nil {
+			return f, fmt.Errorf("invalid interface include pattern: %w", err)
+		}
+		f.include = re
 	}
+	if opts.InterfaceExclude != "" {
+		re, err := regexp.Compile(opts.InterfaceExclude)
+		if err != nil {
+			return f, fmt.Errorf("invalid interface exclude pattern: %w", err)
+		}
+		f.exclude = re
+	}
+	return f, nil
+}
+
+// allowed applies the include/exclude name filters. An empty include
+// matches everything; exclude wins when both match.
+func (f interfaceFilter) allowed(iface string) bool {
+	if f.exclude != nil && f.exclude.MatchString(iface) {
+		return false
+	}
+	if f.include != nil && !f.include.MatchString(iface) {
+		return false
+	}
+	return true
+}
+
+// interfaceAllowed applies the include/exclude name filters.
+func (c *NetworkCollector) interfaceAllowed(iface string) bool {
+	return c.ifaceFilter.allowed(iface)
+}
+
+// instanceTypeAllowed applies the instance_type include filter.
+func (c *NetworkCollector) instanceTypeAllowed(instanceType string) bool {
+	return c.typeInclude == nil || c.typeInclude[instanceType]
 }
 
 // readProcNetDev parses /proc/net/dev and returns counters per interface.
@@ -159,16 +1126,61 @@ func (c *NetworkCollector) Collect(ch chan<- prometheus.Metric) {
 // current process's network namespace. In a container, this would show
 // only the container's interfaces. We use /proc/1/net/dev instead, as
 // PID 1 (host init) is always in the host's network namespace.
-func (c *NetworkCollector) readProcNetDev() (map[string]interfaceStats, error) {
-	path := filepath.Join(c.opts.ProcPath, "1", "net", "dev")
+//
+// Options.HostPID overrides PID 1 when the host init is not PID 1 from the
+// exporter's view. If that file is unreadable (e.g. hardened hosts denying
+// access to PID 1), it falls back to /proc/net/dev, which only reflects the host
+// namespace when the exporter itself runs there. The returned string is the
+// path that was actually read.
+func (c *NetworkCollector) readProcNetDev() (map[string]interfaceStats, string, error) {
+	path := c.opts.hostProcNet("dev")
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		fallback := filepath.Join(c.opts.ProcPath, "net", "dev")
+		ff, ferr := os.Open(fallback)
+		if ferr != nil {
+			return nil, "", err
+		}
+		if !c.procFallbackWarned.Swap(true) {
+			c.logger.Warn("cannot read host counters, falling back to the exporter's own network namespace; interfaces may be missing if not running in the host namespace",
+				"path", path, "fallback", fallback, "error", err)
+		}
+		path, f = fallback, ff
+	} else if c.procFallbackWarned.Swap(false) {
+		c.logger.Info("host counters readable again", "path", path)
 	}
 	defer f.Close()
 
+	result, err := parseProcNetDev(f)
+	return result, path, err
+}
+
+// maxProcLineBytes bounds a single line read from a /proc file. bufio's
+// default 64KB token limit is raised so pathological lines still parse.
+const maxProcLineBytes = 1 << 20
+
+// newProcScanner returns a line scanner over r that accepts lines of up to
+// maxProcLineBytes.
+func newProcScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxProcLineBytes)
+	return scanner
+}
+
+// procScanError annotates a scanner error; an over-long line is reported
+// as such instead of ending the read without explanation.
+func procScanError(err error) error {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("line longer than %d bytes: %w", maxProcLineBytes, err)
+	}
+	return err
+}
+
+// parseProcNetDev parses a /proc/net/dev formatted stream into counters per
+// interface, skipping the two header lines and malformed lines.
+func parseProcNetDev(r io.Reader) (map[string]interfaceStats, error) {
 	result := make(map[string]interfaceStats)
-	scanner := bufio.NewScanner(f)
+	scanner := newProcScanner(r)
 	lineNo := 0
 	for scanner.Scan() {
 		lineNo++
@@ -182,19 +1194,24 @@ func (c *NetworkCollector) readProcNetDev() (map[string]interfaceStats, error) {
 		}
 		result[iface] = s
 	}
-	return result, scanner.Err()
+	return result, procScanError(scanner.Err())
 }
 
 // parseProcNetDevLine parses one line from /proc/net/dev.
 // Format:  iface: rx_bytes rx_packets rx_errs rx_drop rx_fifo rx_frame rx_compressed rx_multicast tx_bytes tx_packets tx_errs tx_drop tx_fifo tx_colls tx_carrier tx_compressed
 func parseProcNetDevLine(line string) (string, interfaceStats, error) {
-	// Split at colon.
-	parts := strings.SplitN(line, ":", 2)
-	if len(parts) != 2 {
+	// The name is right-aligned and padded with spaces, and may itself
+	// contain colons (e.g. "eth0.0:0"); the counters never do, so the
+	// separator is the last colon on the line.
+	idx := strings.LastIndex(line, ":")
+	if idx < 0 {
 		return "", interfaceStats{}, fmt.Errorf("no colon in line")
 	}
-	iface := strings.TrimSpace(parts[0])
-	fields := strings.Fields(parts[1])
+	iface := strings.TrimSpace(line[:idx])
+	if iface == "" {
+		return "", interfaceStats{}, fmt.Errorf("empty interface name")
+	}
+	fields := strings.Fields(line[idx+1:])
 	if len(fields) < 16 {
 		return "", interfaceStats{}, fmt.Errorf("not enough fields")
 	}
@@ -209,44 +1226,120 @@ func parseProcNetDevLine(line string) (string, interfaceStats, error) {
 	}
 
 	return iface, interfaceStats{
-		RxBytes:   vals[0],
-		RxPackets: vals[1],
-		RxErrors:  vals[2],
-		RxDropped: vals[3],
-		TxBytes:   vals[8],
-		TxPackets: vals[9],
-		TxErrors:  vals[10],
-		TxDropped: vals[11],
+		RxBytes:      vals[0],
+		RxPackets:    vals[1],
+		RxErrors:     vals[2],
+		RxDropped:    vals[3],
+		RxFifo:       vals[4],
+		RxFrame:      vals[5],
+		RxCompressed: vals[6],
+		RxMulticast:  vals[7],
+		TxBytes:      vals[8],
+		TxPackets:    vals[9],
+		TxErrors:     vals[10],
+		TxDropped:    vals[11],
+		TxFifo:       vals[12],
+		TxColls:      vals[13],
+		TxCarrier:    vals[14],
+		TxCompressed: vals[15],
 	}, nil
 }
 
-// buildInterfaceInfo resolves metadata for each interface name.
-func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) map[string]interfaceInfo {
+// buildInterfaceInfo resolves metadata for each interface name. When trace
+// is non-nil it also receives the intermediate mappings (for
+// /debug/interfaces).
+func (c *NetworkCollector) buildInterfaceInfo(all map[string]interfaceStats, trace *EnrichmentTrace) map[string]interfaceInfo {
 	sysNetPath := c.sysClassNetPath()
 
+	// Interfaces from other network namespaces are invisible to the host's
+	// sysfs and container runtimes, so only the host's go through enrichment.
+	stats, nsKeys := splitNetnsStats(all)
+
 	// Read operstate for each interface.
 	stateMap := make(map[string]string)
 	for iface := range stats {
 		stateMap[iface] = readFileString(filepath.Join(sysNetPath, iface, "operstate"))
 	}
 
-	// Build bridge membership map: interface → bridge name.
-	bridgeMap := c.buildBridgeMap(stats, sysNetPath)
-
-	// Build ifindex → iface name map for the host.
-	ifindexMap := c.buildIfindexMap(stats, sysNetPath)
+	// Bridge membership, ifindex → iface name and driver names change
+	// rarely, so they come from the (optionally cached) topology. A name
+	// that now has a different ifindex forces a rebuild.
+	c.checkInterfaceIdentity(stats)
+	topo := c.topology(stats, sysNetPath)
+	bridgeMap := topo.bridgeMap
+	bondMap := topo.bondMap
+	ifindexMap := topo.ifindexMap
 
 	// Query Docker for container → veth mapping and network → bridge mapping.
+	start := time.Now()
 	vethToContainer, bridgeToNetwork := c.fetchDockerData(ifindexMap)
+	if len(c.runtimes) > 0 || c.containerd != nil {
+		c.observePhase("docker", start)
+	}
 
 	// Query Incus/LXC for container → veth mapping.
-	vethToIncus := c.buildIncusMapping(ifindexMap)
+	vethToIncus := map[string]string{}
+	if !c.opts.DisableIncus {
+		start = time.Now()
+		vethToIncus = c.buildIncusMapping(ifindexMap)
+		c.observePhase("incus", start)
+	}
+
+	// Query containerd/k8s pod cgroups for pod → veth mapping.
+	vethToPod := c.buildContainerdMapping(ifindexMap)
 
 	// Query midclt/virsh for VM → vnet mapping.
-	vnetToVM := c.buildVMMapping()
+	vnetToVM := map[string]string{}
+	if !c.opts.DisableVM {
+		start = time.Now()
+		vnetToVM = c.buildVMMapping()
+		c.observePhase("vm", start)
+	}
 
 	// Parse VLAN sub-interfaces from /proc/net/vlan/config.
-	vlanMap := c.buildVLANMap()
+	vlanMap := map[string]vlanInfo{}
+	if !c.opts.DisableVLAN {
+		start = time.Now()
+		vlanMap = c.buildVLANMap(c.opts.hostProcNet("vlan", "config"))
+		c.observePhase("vlan", start)
+	}
+
+	// TUN/TAP devices not already claimed by a VM may belong to a VPN daemon.
+	vpnCandidates := make(map[string]bool)
+	for iface := range topo.tunModes {
+		if vnetToVM[iface] == "" && !strings.HasPrefix(iface, "vnet") && !isTailscale(iface) && !isZeroTier(iface) {
+			vpnCandidates[iface] = true
+		}
+	}
+	vpnOwners := c.findVPNOwners(vpnCandidates)
+
+	// Only ask zerotier-cli for network names when a zt port exists.
+	var zeroTierNets map[string]zeroTierNetwork
+	for iface := range stats {
+		if isZeroTier(iface) {
+			nets, err := c.zeroTierNetworks()
+			if err != nil {
+				c.logger.Debug("cannot list ZeroTier networks", "error", err)
+			}
+			zeroTierNets = nets
+			break
+		}
+	}
+
+	if trace != nil {
+		trace.BridgeMap = bridgeMap
+		trace.BondMap = bondMap
+		trace.IfindexMap = ifindexMap
+		trace.VethToContainer = vethToContainer
+		trace.BridgeToNetwork = bridgeToNetwork
+		trace.VethToIncus = vethToIncus
+		trace.VethToPod = vethToPod
+		trace.VnetToVM = vnetToVM
+		trace.VLANMap = vlanMap
+	}
+
+	// Count IPv6 addresses per interface from /proc/1/net/if_inet6.
+	ipv6Counts, hasIPv6 := c.readIPv6AddressCounts(ifindexMap)
 
 	// Build bridge → VLAN mapping: for each bridge, find the VLAN ID of any
 	// VLAN sub-interface that is a member of that bridge.
@@ -263,6 +1356,48 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 			Name:   iface,
 			State:  normalizeState(stateMap[iface]),
 			Bridge: bridgeMap[iface],
+			Bond:   bondMap[iface],
+			Parent: topo.parents[iface],
+		}
+		if info.Bond != "" {
+			info.BondSlave = readBondSlaveState(sysNetPath, iface)
+		}
+
+		// Link speed is only meaningful for physical links; virtual devices
+		// report -1 or fail to read, which leaves SpeedMbps at 0.
+		if speed, err := readFileInt(filepath.Join(sysNetPath, iface, "speed")); err == nil && speed > 0 {
+			info.SpeedMbps = speed
+		}
+
+		// Every netdev has an mtu attribute.
+		if mtu, err := readFileInt(filepath.Join(sysNetPath, iface, "mtu")); err == nil {
+			info.MTU = mtu
+		} else {
+			c.logger.Debug("cannot read interface mtu", "interface", iface, "error", err)
+		}
+
+		// tx_queue_len exists for essentially every netdev; 0 is a valid
+		// value (e.g. noqueue virtual devices), so -1 marks a read error.
+		if qlen, err := readFileInt(filepath.Join(sysNetPath, iface, "tx_queue_len")); err == nil {
+			info.TxQueueLen = qlen
+		} else {
+			info.TxQueueLen = -1
+			c.logger.Debug("cannot read interface tx_queue_len", "interface", iface, "error", err)
+		}
+
+		if v, err := strconv.ParseUint(readFileString(filepath.Join(sysNetPath, iface, "carrier_changes")), 10, 64); err == nil {
+			info.CarrierChanges = v
+			info.HasCarrierChanges = true
+		}
+
+		// Bridges and down links have no readable duplex; report "unknown"
+		// so every interface has exactly one duplex_info series.
+		info.Duplex = normalizeDuplex(readFileString(filepath.Join(sysNetPath, iface, "duplex")))
+
+		info.IPv6Addresses, info.HasIPv6 = ipv6Counts[iface], hasIPv6
+
+		if c.opts.MACLabel {
+			info.MAC = strings.ToLower(readFileString(filepath.Join(sysNetPath, iface, "address")))
 		}
 
 		switch {
@@ -271,23 +1406,42 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 			info.Instance = "loopback"
 			info.App = "system"
 
+		case topo.ovsBridges[iface]:
+			// Open vSwitch bridges carry arbitrary names (e.g. vmbr0).
+			info.InstanceType = "bridge"
+			info.Instance = iface
+			info.App = "system"
+			info.VLAN = bridgeVLAN[iface]
+
 		case strings.HasPrefix(iface, "veth"):
-			// Container veth — check Docker first, then Incus/LXC.
+			// Container veth — check Docker/Podman first, then Incus/LXC, then k8s pods.
 			if ci, ok := vethToContainer[iface]; ok {
-				info.InstanceType = "docker"
+				info.InstanceType = ci.Runtime
 				info.Instance = ci.Name
-				info.App = AppName(ci)
+				info.App = AppNameWithPrefixes(ci, c.opts.AppPrefixStrip)
+				info.Service = ci.Labels[composeServiceLabel]
+				info.ContainerMACs = containerMACs(ci)
+				if ci.Runtime == "docker" {
+					info.RestartCount, info.HasRestartCount = ci.RestartCount, true
+				}
+				if c.opts.IPLabel {
+					info.IP = containerIP(ci, bridgeToNetwork[bridgeMap[iface]])
+				}
 			} else if incusName, ok := vethToIncus[iface]; ok {
 				info.InstanceType = "incus"
 				info.Instance = incusName
 				info.App = incusName
+			} else if podName, ok := vethToPod[iface]; ok {
+				info.InstanceType = "k8s"
+				info.Instance = podName
+				info.App = podName
 			} else {
 				info.InstanceType = "docker"
 				info.Instance = iface
 				// Derive app from the parent bridge's Docker network.
 				if br, ok := bridgeMap[iface]; ok {
 					if netInfo, ok := bridgeToNetwork[br]; ok {
-						info.App = appNameFromDockerNetwork(netInfo.Name)
+						info.App = appNameFromDockerNetwork(netInfo.Name, c.opts.AppPrefixStrip)
 					}
 				}
 			}
@@ -295,9 +1449,16 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 			if br := bridgeMap[iface]; br != "" {
 				info.VLAN = bridgeVLAN[br]
 			}
+			if c.appCollapsed(info.InstanceType, info.App) {
+				info.Instance = otherApp
+				info.App = otherApp
+				info.IP = ""
+				info.Collapsed = true
+			}
 
-		case strings.HasPrefix(iface, "vnet"):
-			// VM network interface (libvirt tap/tun).
+		case strings.HasPrefix(iface, "vnet") ||
+			(strings.HasPrefix(iface, "tap") && vnetToVM[iface] != ""):
+			// VM network interface (libvirt tap/tun, or bhyve tap on FreeBSD).
 			info.InstanceType = "vm"
 			if vmName, ok := vnetToVM[iface]; ok {
 				info.Instance = vmName
@@ -319,6 +1480,74 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 				info.Instance = iface
 			}
 
+		case isIPVLAN(iface, topo.devTypes[iface], topo.kinds[iface]):
+			// ipvlan devices follow no naming convention; the rtnetlink
+			// kind identifies them.
+			info.InstanceType = "ipvlan"
+			info.IPVLANMode = topo.kinds[iface].IPVLANMode
+			if vmName, ok := vnetToVM[iface]; ok {
+				info.Instance = vmName
+				info.App = vmName
+			} else {
+				info.Instance = iface
+			}
+
+		case topo.physFns[iface] != "":
+			// SR-IOV VF still bound to a host driver. VFs passed through to
+			// a guest are bound to vfio-pci and have no host netdev at all.
+			info.InstanceType = "sriov_vf"
+			info.Instance = iface
+			info.App = "system"
+			info.Parent = topo.physFns[iface]
+			info.Driver = topo.drivers[iface]
+
+		case topo.bonds[iface]:
+			info.InstanceType = "bond"
+			info.Instance = iface
+			info.App = "system"
+			info.VLAN = bridgeVLAN[bridgeMap[iface]]
+
+		case isWireGuard(iface, topo.devTypes[iface]):
+			info.InstanceType = "wireguard"
+			info.Instance = iface
+			info.App = "wireguard"
+			if peers, err := c.wireGuardPeerCount(iface); err == nil {
+				info.WireGuardPeers = peers
+			} else {
+				info.WireGuardPeers = -1
+				c.logger.Debug("cannot count WireGuard peers", "interface", iface, "error", err)
+			}
+
+		case isTailscale(iface):
+			info.InstanceType = "tailscale"
+			info.Instance = iface
+			info.App = "tailscale"
+			if peers, err := c.tailscalePeerCount(); err == nil {
+				info.TailscalePeers = peers
+			} else {
+				info.TailscalePeers = -1
+				c.logger.Debug("cannot count Tailscale peers", "interface", iface, "error", err)
+			}
+
+		case isZeroTier(iface):
+			info.InstanceType = "zerotier"
+			info.Instance = iface
+			info.App = "zerotier"
+			if nw, ok := zeroTierNets[iface]; ok {
+				info.ZeroTierNetworkID = nw.ID
+				info.ZeroTierNetworkName = nw.Name
+			}
+
+		case topo.tunModes[iface] == "tun" && vpnCandidates[iface], vpnOwners[iface] != "":
+			// Layer-3 tun devices are VPN tunnels in practice; tap devices
+			// only count when a VPN daemon holds them open.
+			info.InstanceType = "vpn"
+			info.Instance = iface
+			info.App = "system"
+			if owner := vpnOwners[iface]; owner != "" {
+				info.App = owner
+			}
+
 		case strings.HasPrefix(iface, "vlan"):
 			info.InstanceType = "vlan"
 			info.Instance = iface
@@ -328,15 +1557,21 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 			}
 
 		case strings.HasPrefix(iface, "br-") || strings.HasPrefix(iface, "br") ||
-			strings.HasPrefix(iface, "docker") || strings.HasPrefix(iface, "incus"):
+			strings.HasPrefix(iface, "docker") || strings.HasPrefix(iface, "incus") ||
+			strings.HasPrefix(iface, "podman"):
 			info.InstanceType = "bridge"
 			info.VLAN = bridgeVLAN[iface]
-			// Only use Docker network name for hash-named bridges (br-<hash>).
-			// Well-known bridges (br0, docker0, incusbr0) keep their own name.
-			if strings.HasPrefix(iface, "br-") {
+			if netInfo, ok := bridgeToNetwork[iface]; ok {
+				info.DockerNetwork = &netInfo
+				info.NetDriver = netInfo.Driver
+			}
+			// Only use Docker network name for hash-named bridges (br-<hash>)
+			// and numbered Podman bridges (podman1, ...). Well-known bridges
+			// (br0, docker0, incusbr0, podman0) keep their own name.
+			if strings.HasPrefix(iface, "br-") || (strings.HasPrefix(iface, "podman") && iface != "podman0") {
 				if netInfo, ok := bridgeToNetwork[iface]; ok {
 					info.Instance = netInfo.Name
-					info.App = appNameFromDockerNetwork(netInfo.Name)
+					info.App = appNameFromDockerNetwork(netInfo.Name, c.opts.AppPrefixStrip)
 				} else {
 					info.Instance = iface
 				}
@@ -347,8 +1582,7 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 
 		default:
 			// Check if it's a physical device (has a device/driver symlink in sysfs).
-			driverPath := filepath.Join(sysNetPath, iface, "device", "driver")
-			if _, err := os.Readlink(driverPath); err == nil {
+			if topo.drivers[iface] != "" {
 				info.InstanceType = "physical"
 			} else {
 				info.InstanceType = "unknown"
@@ -360,20 +1594,48 @@ func (c *NetworkCollector) buildInterfaceInfo(stats map[string]interfaceStats) m
 				info.InstanceType = "vlan"
 				info.VLAN = vi.ID
 			}
+			if info.InstanceType == "physical" {
+				info.Driver = topo.drivers[iface]
+				info.Queues, info.HasQueues = readQueueCounts(sysNetPath, iface)
+			}
+		}
+		// macvlan/ipvlan networks attach containers directly to a host
+		// interface (a NIC, bond or VLAN) rather than a bridge.
+		if netInfo, ok := bridgeToNetwork[iface]; ok && netInfo.Parent == iface {
+			info.DockerNetwork = &netInfo
+			info.NetDriver = netInfo.Driver
+		}
+
+		c.applyClassifyRules(&info)
+		if info.InstanceType == "bridge" {
+			info.FDBEntries, info.HasFDB = readFDBEntryCount(sysNetPath, iface)
+		}
+		info.Role = bridgeRole(sysNetPath, info)
+		if info.Bridge != "" {
+			info.Uplink = topo.uplinks[info.Bridge]
+		} else {
+			info.Uplink = topo.uplinks[iface]
 		}
 
+		if c.netnsLabel {
+			info.Netns = defaultNetns
+		}
 		result[iface] = info
 	}
 
+	nsVLANs := c.netnsVLANMaps(nsKeys)
+	for _, key := range nsKeys {
+		info := netnsInterfaceInfo(key, nsVLANs)
+		c.applyClassifyRules(&info)
+		result[key] = info
+	}
+
 	return result
 }
 
 // sysClassNetPath returns the path to /sys/class/net (respecting container paths).
 func (c *NetworkCollector) sysClassNetPath() string {
-	if c.opts.IsContainer() {
-		return filepath.Join(c.opts.RootfsPath, "sys", "class", "net")
-	}
-	return "/sys/class/net"
+	return c.opts.sysClassNetPath()
 }
 
 // vlanInfo describes one 802.1Q VLAN sub-interface.
@@ -382,26 +1644,30 @@ type vlanInfo struct {
 	Parent string // Parent device (e.g., "eno1")
 }
 
-// buildVLANMap parses /proc/net/vlan/config to discover 802.1Q VLAN
-// sub-interfaces. Returns a map from interface name to VLAN info.
+// buildVLANMap parses a network namespace's /proc/net/vlan/config (path)
+// to discover its 802.1Q VLAN sub-interfaces. Returns a map from interface
+// name to VLAN info.
 //
 // Format of /proc/net/vlan/config:
 //
 //	VLAN Dev name       | VLAN ID
 //	Name-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD
 //	eno1.100           | 100  | eno1
-func (c *NetworkCollector) buildVLANMap() map[string]vlanInfo {
+func (c *NetworkCollector) buildVLANMap(path string) map[string]vlanInfo {
 	result := make(map[string]vlanInfo)
 
-	path := filepath.Join(c.opts.ProcPath, "1", "net", "vlan", "config")
 	f, err := os.Open(path)
 	if err != nil {
+		// A missing file just means the 8021q module isn't loaded.
+		if !os.IsNotExist(err) {
+			c.recordError("vlan")
+		}
 		c.logger.Debug("VLAN config not available", "path", path, "error", err)
 		return result
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
+	scanner := newProcScanner(f)
 	for scanner.Scan() {
 		line := scanner.Text()
 		// Skip headers and blank lines.
@@ -420,6 +1686,10 @@ func (c *NetworkCollector) buildVLANMap() map[string]vlanInfo {
 			result[devName] = vlanInfo{ID: vlanID, Parent: parent}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		c.recordError("vlan")
+		c.logger.Warn("cannot read VLAN config", "path", path, "error", procScanError(err))
+	}
 
 	if len(result) > 0 {
 		c.logger.Debug("discovered VLAN interfaces", "count", len(result))
@@ -439,6 +1709,11 @@ func (c *NetworkCollector) buildBridgeMap(stats map[string]interfaceStats, sysNe
 			continue
 		}
 		bridgeName := filepath.Base(target)
+		// Bond slaves also have a master; those are handled by buildBondMap.
+		// OVS ports point at the datapath device; see buildOVSBridgeMap.
+		if bridgeName == ovsDatapath || isBondMaster(sysNetPath, bridgeName) {
+			continue
+		}
 		bridgeMap[iface] = bridgeName
 	}
 	return bridgeMap
@@ -456,50 +1731,135 @@ func (c *NetworkCollector) buildIfindexMap(stats map[string]interfaceStats, sysN
 	return m
 }
 
-// fetchDockerData queries the Docker API and returns:
+// containerRuntime is one Docker-API-compatible daemon (Docker or Podman)
+// queried for container and network mapping.
+type containerRuntime struct {
+	name     string // "docker" or "podman"; used as instance_type and error subsystem
+	endpoint string // socket path or URL the client talks to
+	client   *DockerClient
+	// up is the result of the latest /version probe, made while building
+	// enrichment or, when enrichment is served from cache, on each
+	// background refresh. Collect only reads it.
+	up *atomic.Bool
+}
+
+// fetchDockerData queries every configured Docker daemon (and Podman, if
+// configured) and returns:
 // 1. A mapping from host-side veth interfaces to their owning containers.
 // 2. A mapping from bridge interface names to their Docker network info.
 func (c *NetworkCollector) fetchDockerData(ifindexMap map[int]string) (map[string]ContainerInfo, map[string]DockerNetworkInfo) {
 	vethMap := make(map[string]ContainerInfo)
 	netMap := make(map[string]DockerNetworkInfo)
 
-	client := NewDockerClient(c.dockerSocket)
-	if !client.Available() {
-		c.logger.Debug("docker socket not available, skipping container/network mapping")
-		return vethMap, netMap
+	hostNet := make(map[string]string)
+	available := false
+	for _, rt := range c.runtimes {
+		if c.fetchRuntimeData(rt, ifindexMap, vethMap, netMap, hostNet) {
+			available = true
+		}
+	}
+	c.hostNetMu.Lock()
+	c.hostNet = hostNet
+	c.hostNetMu.Unlock()
+	if c.containerd != nil && !available {
+		c.fetchContainerdData(ifindexMap, vethMap)
+	}
+
+	return vethMap, netMap
+}
+
+// fetchRuntimeData merges one runtime's veth → container and bridge →
+// network mappings into vethMap and netMap, and its host-network containers
+// into hostNet. Earlier runtimes win on conflicts. It reports whether the
+// runtime's API answered.
+func (c *NetworkCollector) fetchRuntimeData(rt containerRuntime, ifindexMap map[int]string, vethMap map[string]ContainerInfo, netMap map[string]DockerNetworkInfo, hostNet map[string]string) bool {
+	client := rt.client
+	up := client.Available(c.ctx)
+	rt.up.Store(up)
+	if !up {
+		c.logger.Debug("container runtime socket not available, skipping container/network mapping", "runtime", rt.name, "endpoint", rt.endpoint)
+		return false
 	}
 
-	// Map containers to their host-side veth interfaces.
-	containers, err := client.ListContainers()
-	if err != nil {
-		c.logger.Warn("failed to list docker containers", "error", err)
+	// Map containers to their host-side veth interfaces. A remote daemon's
+	// PIDs belong to another host, so looking them up in the local procfs
+	// would attribute local veths to unrelated containers.
+	if client.Remote() {
+		c.logger.Debug("remote container runtime, skipping container to veth mapping", "runtime", rt.name, "endpoint", rt.endpoint)
+	} else if containers, err := client.ListContainers(c.ctx); err != nil {
+		c.warnFailure("containers "+rt.endpoint, "failed to list containers", "runtime", rt.name, "endpoint", rt.endpoint, "error", err)
+		c.recordError(rt.name)
 	} else {
+		c.clearFailure("containers "+rt.endpoint, "listing containers recovered", "runtime", rt.name, "endpoint", rt.endpoint)
+		var unresolved []ContainerInfo
 		for _, ci := range containers {
 			if ci.PID <= 0 {
 				continue
 			}
+			ci.Runtime = rt.name
+			if ci.HostNetwork {
+				// Its "container" sysfs is the host's; there is no veth to find.
+				if _, taken := hostNet[ci.Name]; !taken {
+					hostNet[ci.Name] = AppNameWithPrefixes(ci, c.opts.AppPrefixStrip)
+				}
+				continue
+			}
 			iflinks := c.findContainerIflinks(c.opts.ProcPath, ci.PID)
+			if len(iflinks) == 0 {
+				unresolved = append(unresolved, ci)
+				continue
+			}
 			for _, hostIfindex := range iflinks {
-				if hostIface, ok := ifindexMap[hostIfindex]; ok {
+				hostIface, ok := ifindexMap[hostIfindex]
+				if !ok {
+					continue
+				}
+				prev, taken := vethMap[hostIface]
+				switch {
+				case !taken:
 					vethMap[hostIface] = ci
+				case prev.ID != ci.ID:
+					vethMap[hostIface] = c.resolveVethConflict(hostIface, hostIfindex, prev, ci)
 				}
 			}
 		}
+
+		// The container's sysfs is unreadable (user namespaces, restricted
+		// mounts); fall back to matching host veth peers against the
+		// ifindexes visible in /proc/<PID>/net.
+		matched := c.matchVethPeers(unresolved, ifindexMap, vethMap)
+		for _, ci := range unresolved {
+			if !matched[ci.ID] {
+				// A cached PID may be stale after a restart; re-inspect next time.
+				client.InvalidateContainer(ci.ID)
+			}
+		}
 	}
 
-	// Map Docker bridge interfaces to their network names.
-	networks, err := client.ListNetworks()
+	// Map bridge interfaces (and macvlan/ipvlan parents) to their network
+	// names.
+	networks, err := client.ListNetworks(c.ctx)
 	if err != nil {
-		c.logger.Warn("failed to list docker networks", "error", err)
+		c.warnFailure("networks "+rt.endpoint, "failed to list networks", "runtime", rt.name, "endpoint", rt.endpoint, "error", err)
+		c.recordError(rt.name)
 	} else {
+		c.clearFailure("networks "+rt.endpoint, "listing networks recovered", "runtime", rt.name, "endpoint", rt.endpoint)
 		for _, n := range networks {
-			if n.BridgeName != "" {
-				netMap[n.BridgeName] = n
+			iface := cmp.Or(n.BridgeName, n.Parent)
+			if iface == "" {
+				continue
+			}
+			if prev, taken := netMap[iface]; taken {
+				if prev.ID != n.ID {
+					c.logger.Warn("interface claimed by multiple networks, keeping the first",
+						"interface", iface, "kept", prev.Name, "ignored", n.Name, "endpoint", rt.endpoint)
+				}
+				continue
 			}
+			netMap[iface] = n
 		}
 	}
-
-	return vethMap, netMap
+	return true
 }
 
 // findContainerIflinks reads the iflink values for all non-lo interfaces in a
@@ -528,7 +1888,9 @@ func (c *NetworkCollector) findContainerIflinks(procPath string, pid int) []int
 }
 
 // buildVMMapping maps vnet/macvtap interfaces to VM names.
-// It first tries the TrueNAS midclt API, then falls back to virsh.
+// It first tries the TrueNAS midclt API, then falls back to virsh, then to
+// the guest names on QEMU command lines, and on FreeBSD finally to bhyve
+// process/tap ownership.
 func (c *NetworkCollector) buildVMMapping() map[string]string {
 	result := make(map[string]string)
 
@@ -552,16 +1914,41 @@ func (c *NetworkCollector) buildVMMapping() map[string]string {
 	// Fall back to virsh.
 	vmNames, err := c.runVirshListNames()
 	if err != nil {
-		c.logger.Debug("vm mapping not available (neither midclt nor virsh)", "error", err)
+		// Plain KVM hosts without libvirt tooling: read -name from QEMU cmdlines.
+		if vms, qerr := c.findQEMUProcesses(); qerr == nil {
+			for _, vm := range vms {
+				for _, iface := range c.findQEMUInterfaces(vm.pid) {
+					result[iface] = vm.name
+				}
+			}
+			if len(result) > 0 {
+				c.logger.Debug("mapped VMs via QEMU cmdline", "count", len(result))
+				return result
+			}
+		}
+
+		// TrueNAS CORE / FreeBSD: bhyve instead of libvirt.
+		if runtime.GOOS == "freebsd" {
+			bhyve, berr := c.buildBhyveMapping()
+			if berr != nil {
+				c.logger.Debug("vm mapping not available (neither midclt, virsh, QEMU nor bhyve)", "error", berr)
+			} else {
+				c.logger.Debug("mapped VMs via bhyve", "count", len(bhyve))
+			}
+			return bhyve
+		}
+		c.logger.Debug("vm mapping not available (neither midclt, virsh nor QEMU)", "error", err)
 		return result
 	}
 
 	for _, vmName := range vmNames {
 		ifaces, err := c.runVirshDomIfList(vmName)
 		if err != nil {
-			c.logger.Debug("failed to get VM interfaces", "vm", vmName, "error", err)
+			c.warnFailure("virsh "+vmName, "failed to get VM interfaces", "vm", vmName, "error", err)
+			c.recordError("vm")
 			continue
 		}
+		c.clearFailure("virsh "+vmName, "getting VM interfaces recovered", "vm", vmName)
 		for _, iface := range ifaces {
 			result[iface] = vmName
 		}
@@ -578,12 +1965,14 @@ type vmEntry struct {
 
 // queryMidcltVMs queries the TrueNAS middleware for running VMs.
 func (c *NetworkCollector) queryMidcltVMs() ([]vmEntry, error) {
-	cmd := c.buildCommand("midclt", "call", "vm.query")
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "midclt", "call", "vm.query")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
 	if err := cmd.Run(); err != nil {
-		return nil, err
+		return nil, execError(ctx, c.logger, "midclt", err)
 	}
 
 	var raw []struct {
@@ -680,12 +2069,14 @@ func (c *NetworkCollector) resolveIfindex(idx int) string {
 
 // runVirshListNames returns the names of all running VMs.
 func (c *NetworkCollector) runVirshListNames() ([]string, error) {
-	cmd := c.buildCommand("virsh", "list", "--name", "--state-running")
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "virsh", "list", "--name", "--state-running")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
 	if err := cmd.Run(); err != nil {
-		return nil, err
+		return nil, execError(ctx, c.logger, "virsh", err)
 	}
 
 	var names []string
@@ -701,12 +2092,14 @@ func (c *NetworkCollector) runVirshListNames() ([]string, error) {
 
 // runVirshDomIfList returns the host-side interface names for a VM.
 func (c *NetworkCollector) runVirshDomIfList(vmName string) ([]string, error) {
-	cmd := c.buildCommand("virsh", "domiflist", vmName)
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "virsh", "domiflist", vmName)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
 	if err := cmd.Run(); err != nil {
-		return nil, err
+		return nil, execError(ctx, c.logger, "virsh", err)
 	}
 
 	var ifaces []string
@@ -729,20 +2122,73 @@ func (c *NetworkCollector) runVirshDomIfList(vmName string) ([]string, error) {
 	return ifaces, nil
 }
 
-// appNameFromDockerNetwork extracts a TrueNAS app name from a Docker
-// network name. TrueNAS apps create networks named "ix-<appname>_<suffix>".
-func appNameFromDockerNetwork(networkName string) string {
-	if !strings.HasPrefix(networkName, "ix-") {
+// containerIP returns the container's IP address on the Docker network
+// backing the veth's bridge. If the bridge's network is unknown and the
+// container is attached to a single network, that network's IP is used.
+func containerIP(ci ContainerInfo, netInfo DockerNetworkInfo) string {
+	for name, ep := range ci.Networks {
+		if netInfo.ID != "" && (ep.NetworkID == netInfo.ID || name == netInfo.Name) {
+			return ep.IPAddress
+		}
+	}
+	if len(ci.Networks) == 1 {
+		for _, ep := range ci.Networks {
+			return ep.IPAddress
+		}
+	}
+	return ""
+}
+
+// containerMACs returns the non-empty MAC addresses of ci keyed by network
+// name, or nil if there are none (e.g. host-network containers).
+func containerMACs(ci ContainerInfo) map[string]string {
+	var macs map[string]string
+	for name, ep := range ci.Networks {
+		if ep.MacAddress == "" {
+			continue
+		}
+		if macs == nil {
+			macs = make(map[string]string, len(ci.Networks))
+		}
+		macs[name] = strings.ToLower(ep.MacAddress)
+	}
+	return macs
+}
+
+// appNameFromDockerNetwork extracts an app name from a Docker network name
+// that starts with one of prefixes. TrueNAS apps create networks named
+// "ix-<appname>_<suffix>"; networks without a matching prefix yield "".
+func appNameFromDockerNetwork(networkName string, prefixes []string) string {
+	name, ok := trimAppPrefix(networkName, prefixes)
+	if !ok {
 		return ""
 	}
-	name := strings.TrimPrefix(networkName, "ix-")
 	if idx := strings.Index(name, "_"); idx > 0 {
 		return name[:idx]
 	}
 	return name
 }
 
-// buildIncusMapping discovers Incus/LXC containers by scanning /proc for
+// buildIncusMapping maps host-side veth interfaces to Incus/LXC container
+// names. With Options.IncusSocket set it asks the Incus API, falling back
+// to the cgroup scan if the API fails; otherwise it only scans cgroups.
+func (c *NetworkCollector) buildIncusMapping(ifindexMap map[int]string) map[string]string {
+	if c.incus != nil {
+		result, err := c.buildIncusAPIMapping(ifindexMap)
+		if err == nil {
+			c.clearFailure("incus api", "Incus API recovered", "socket", c.opts.IncusSocket)
+			if len(result) > 0 {
+				c.logger.Debug("mapped Incus instances via API", "count", len(result))
+			}
+			return result
+		}
+		c.warnFailure("incus api", "Incus API unavailable, falling back to cgroup scan", "socket", c.opts.IncusSocket, "error", err)
+		c.recordError("incus")
+	}
+	return c.scanIncusCgroups(ifindexMap)
+}
+
+// scanIncusCgroups discovers Incus/LXC containers by scanning /proc for
 // processes in LXC cgroups and maps their host-side veth interfaces.
 //
 // LXC containers have a cgroup path like:
@@ -751,14 +2197,17 @@ func appNameFromDockerNetwork(networkName string) string {
 //
 // We look for init processes (the ones with /init.scope) and use the same
 // iflink technique as Docker to find their host-side veth interfaces.
-func (c *NetworkCollector) buildIncusMapping(ifindexMap map[int]string) map[string]string {
+func (c *NetworkCollector) scanIncusCgroups(ifindexMap map[int]string) map[string]string {
 	result := make(map[string]string)
 
 	procDir := c.opts.ProcPath
 	entries, err := os.ReadDir(procDir)
 	if err != nil {
+		c.warnFailure("incus procfs", "cannot scan procfs for Incus/LXC containers", "path", procDir, "error", err)
+		c.recordError("incus")
 		return result
 	}
+	c.clearFailure("incus procfs", "procfs scan for Incus/LXC containers recovered", "path", procDir)
 
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -775,14 +2224,14 @@ func (c *NetworkCollector) buildIncusMapping(ifindexMap map[int]string) map[stri
 			continue
 		}
 
-		// Look for the pattern "lxc.payload.<name>/init.scope".
-		// Only match init.scope to avoid scanning all container processes.
+		// Look for "lxc.payload.<name>" or "lxc.payload.<name>/init.scope"
+		// on any cgroup v1 or v2 line.
 		containerName := parseLXCCgroup(cgroupData)
 		if containerName == "" {
 			continue
 		}
 
-		// Skip if we already mapped this container (multiple init.scope PIDs).
+		// Skip if we already mapped this container (several matching PIDs).
 		alreadyMapped := false
 		for _, name := range result {
 			if name == containerName {
@@ -811,36 +2260,45 @@ func (c *NetworkCollector) buildIncusMapping(ifindexMap map[int]string) map[stri
 }
 
 // parseLXCCgroup extracts the LXC container name from a cgroup file content.
-// Returns empty string if not an LXC container init process.
-// Expected format: "0::/lxc.payload.backupserver/init.scope"
+// It accepts the unified cgroup v2 line and the per-controller cgroup v1
+// lines alike:
+//
+//	0::/lxc.payload.backupserver/init.scope
+//	1:name=systemd:/lxc.payload.backupserver/init.scope
+//	4:memory:/lxc.payload.backupserver
+//
+// Only processes in a container's top-level payload cgroup or its
+// init.scope match; processes further down (systemd services, nested
+// containers) return "" so they are not attributed to the outer container.
 func parseLXCCgroup(data string) string {
 	for _, line := range strings.Split(data, "\n") {
-		idx := strings.Index(line, "lxc.payload.")
-		if idx < 0 {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
 			continue
 		}
-		rest := line[idx+len("lxc.payload."):]
-		slashIdx := strings.Index(rest, "/")
-		if slashIdx <= 0 {
+		path := parts[2]
+		idx := strings.Index(path, "/lxc.payload.")
+		if idx < 0 {
 			continue
 		}
-		// Only match init processes to avoid duplicates.
-		suffix := rest[slashIdx:]
-		if suffix != "/init.scope" {
+		name := strings.TrimSuffix(path[idx+len("/lxc.payload."):], "/init.scope")
+		if name == "" || strings.Contains(name, "/") {
 			continue
 		}
-		return rest[:slashIdx]
+		return name
 	}
 	return ""
 }
 
 // buildCommand creates an exec.Cmd that optionally uses chroot for container mode.
-func (c *NetworkCollector) buildCommand(name string, args ...string) *exec.Cmd {
-	if c.opts.IsContainer() {
-		chrootArgs := append([]string{c.opts.RootfsPath, name}, args...)
-		return exec.Command("chroot", chrootArgs...)
-	}
-	return exec.Command(name, args...)
+func (c *NetworkCollector) buildCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return c.opts.buildCommand(ctx, name, args...)
+}
+
+// execContext returns a context for one external command, bounded by the
+// collector's lifetime and Options.ExecTimeout.
+func (c *NetworkCollector) execContext() (context.Context, context.CancelFunc) {
+	return c.opts.execContext(c.ctx)
 }
 
 // readFileString reads the entire contents of a file, returning the trimmed
@@ -853,6 +2311,30 @@ func readFileString(path string) string {
 	return strings.TrimSpace(string(data))
 }
 
+// normalizeDuplex maps sysfs duplex values to "full", "half", or "unknown".
+func normalizeDuplex(s string) string {
+	switch s = strings.ToLower(s); s {
+	case "full", "half":
+		return s
+	default:
+		return "unknown"
+	}
+}
+
+// boolToFloat converts a boolean to a 1/0 gauge value.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// readFileInt reads a file containing a single (possibly negative) integer,
+// as found in most sysfs attributes.
+func readFileInt(path string) (int64, error) {
+	return strconv.ParseInt(readFileString(path), 10, 64)
+}
+
 // normalizeState converts sysfs operstate to a cleaner string.
 func normalizeState(s string) string {
 	s = strings.TrimSpace(strings.ToLower(s))
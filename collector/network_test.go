@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestInterfaceFilter(t *testing.T) {
+	tests := []struct {
+		include, exclude string
+		iface            string
+		want             bool
+	}{
+		{"", "", "eno1", true},
+		{"^eno", "", "eno1", true},
+		{"^eno", "", "veth1234", false},
+		{"", "^veth", "veth1234", false},
+		{"", "^veth", "eno1", true},
+		{"^eno", "^eno2$", "eno2", false}, // exclude wins
+	}
+	for _, tt := range tests {
+		f, err := newInterfaceFilter(Options{InterfaceInclude: tt.include, InterfaceExclude: tt.exclude})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.allowed(tt.iface); got != tt.want {
+			t.Errorf("include %q exclude %q: allowed(%s) = %v, want %v", tt.include, tt.exclude, tt.iface, got, tt.want)
+		}
+	}
+}
+
+func TestEthtoolCollectorInvalidFilter(t *testing.T) {
+	_, err := NewEthtoolCollector(context.Background(), slog.New(slog.DiscardHandler), Options{InterfaceExclude: "("})
+	if err == nil {
+		t.Error("NewEthtoolCollector accepted an invalid exclude pattern")
+	}
+}
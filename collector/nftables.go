@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NFTablesCollector exposes the counters of commented nftables rules, read
+// from `nft -j list ruleset` on each scrape. Rules without both a counter
+// statement and a comment are ignored.
+type NFTablesCollector struct {
+	ctx    context.Context
+	opts   Options
+	logger *slog.Logger
+
+	ruleBytes   *prometheus.Desc
+	rulePackets *prometheus.Desc
+}
+
+// NewNFTablesCollector returns a collector that runs `nft -j list ruleset`
+// on each scrape. Cancelling ctx kills any nft process still running.
+func NewNFTablesCollector(ctx context.Context, logger *slog.Logger, opts Options) *NFTablesCollector {
+	labels := []string{"family", "table", "chain", "comment"}
+	return &NFTablesCollector{
+		ctx:    ctx,
+		opts:   opts,
+		logger: logger,
+		ruleBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_nft_rule_bytes_total"),
+			"Bytes matched by commented nftables rules with a counter.",
+			labels, nil,
+		),
+		rulePackets: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.MetricNamespace, "", "net_nft_rule_packets_total"),
+			"Packets matched by commented nftables rules with a counter.",
+			labels, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *NFTablesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ruleBytes
+	ch <- c.rulePackets
+}
+
+// Collect implements prometheus.Collector. A missing or failing nft yields
+// no series.
+func (c *NFTablesCollector) Collect(ch chan<- prometheus.Metric) {
+	rules, err := c.listRuleCounters()
+	if err != nil {
+		c.logger.Debug("nft list ruleset failed", "error", err)
+		return
+	}
+	for key, ctr := range rules {
+		ch <- prometheus.MustNewConstMetric(c.ruleBytes, prometheus.CounterValue, float64(ctr.Bytes), key.family, key.table, key.chain, key.comment)
+		ch <- prometheus.MustNewConstMetric(c.rulePackets, prometheus.CounterValue, float64(ctr.Packets), key.family, key.table, key.chain, key.comment)
+	}
+}
+
+// nftRuleKey identifies one emitted series; rules sharing a comment within
+// a chain are summed.
+type nftRuleKey struct {
+	family, table, chain, comment string
+}
+
+// nftCounter is an anonymous counter statement's values.
+type nftCounter struct {
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// listRuleCounters runs `nft -j list ruleset` and returns the counters of
+// its commented rules.
+func (c *NFTablesCollector) listRuleCounters() (map[nftRuleKey]nftCounter, error) {
+	ctx, cancel := c.opts.execContext(c.ctx)
+	defer cancel()
+	cmd := c.opts.buildCommand(ctx, "nft", "-j", "list", "ruleset")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return nil, execError(ctx, c.logger, "nft", err)
+	}
+	return parseNFTRuleCounters(out.Bytes())
+}
+
+// parseNFTRuleCounters extracts commented rule counters from nft's JSON
+// output:
+//
+//	{"nftables": [{"rule": {"family": "inet", "table": "filter",
+//	  "chain": "forward", "comment": "tenant-a",
+//	  "expr": [..., {"counter": {"packets": 10, "bytes": 1400}}]}}, ...]}
+//
+// Named counters ({"counter": "name"}) carry no values and are skipped.
+func parseNFTRuleCounters(data []byte) (map[nftRuleKey]nftCounter, error) {
+	var doc struct {
+		Nftables []struct {
+			Rule *struct {
+				Family  string                       `json:"family"`
+				Table   string                       `json:"table"`
+				Chain   string                       `json:"chain"`
+				Comment string                       `json:"comment"`
+				Expr    []map[string]json.RawMessage `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("nft unmarshal ruleset: %w", err)
+	}
+
+	result := make(map[nftRuleKey]nftCounter)
+	for _, obj := range doc.Nftables {
+		rule := obj.Rule
+		if rule == nil || rule.Comment == "" {
+			continue
+		}
+		for _, expr := range rule.Expr {
+			raw, ok := expr["counter"]
+			if !ok {
+				continue
+			}
+			var ctr nftCounter
+			if err := json.Unmarshal(raw, &ctr); err != nil {
+				continue
+			}
+			key := nftRuleKey{rule.Family, rule.Table, rule.Chain, rule.Comment}
+			sum := result[key]
+			sum.Packets += ctr.Packets
+			sum.Bytes += ctr.Bytes
+			result[key] = sum
+		}
+	}
+	return result, nil
+}
@@ -1,5 +1,40 @@
 package collector
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hostProcNet returns the path of a file under the host namespace's
+// <ProcPath>/<HostPID>/net/ directory.
+func (o Options) hostProcNet(elem ...string) string {
+	pid := o.HostPID
+	if pid <= 0 {
+		pid = 1
+	}
+	return filepath.Join(append([]string{o.ProcPath, strconv.Itoa(pid), "net"}, elem...)...)
+}
+
+// Interface counter backends selectable via Options.StatsBackend.
+const (
+	// StatsBackendProcfs parses <ProcPath>/<HostPID>/net/dev (host namespace).
+	StatsBackendProcfs = "procfs"
+	// StatsBackendNetlink dumps links via RTM_GETLINK in the exporter's
+	// own network namespace.
+	StatsBackendNetlink = "netlink"
+	// StatsBackendSysfs reads /sys/class/net/<iface>/statistics/ files
+	// (under RootfsPath in container mode).
+	StatsBackendSysfs = "sysfs"
+)
+
 // Options holds configuration options shared by all collectors,
 // primarily for running inside containers where host paths are mounted
 // at non-standard locations.
@@ -7,9 +42,208 @@ type Options struct {
 	// ProcPath is the procfs mount point (default "/proc", use "/host/proc" in containers).
 	ProcPath string
 
+	// HostPID is a process in the host's network namespace whose
+	// <ProcPath>/<HostPID>/net/ files are read (default 1, the host init).
+	HostPID int
+
 	// RootfsPath is the host root filesystem mount point (default "/", use "/host" in containers).
 	// When set to something other than "/", commands are executed via chroot.
 	RootfsPath string
+
+	// RefreshInterval enables background collection: counters are re-read on
+	// this interval and scrapes are served from the latest snapshot. Zero
+	// (the default) gathers everything on demand during each scrape.
+	RefreshInterval time.Duration
+
+	// EnrichmentTTL is how long resolved interface metadata (Docker, VM,
+	// Incus, VLAN, bridge labels) is reused in background mode before it is
+	// rebuilt. New interfaces always trigger an immediate rebuild.
+	EnrichmentTTL time.Duration
+
+	// ScrapeDedupeWindow is how long an on-demand snapshot is reused by
+	// later scrapes. Scrapes that arrive while a collection is in flight
+	// always wait for and share its result; zero disables reuse beyond that.
+	// Ignored in background mode.
+	ScrapeDedupeWindow time.Duration
+
+	// InterfaceInclude is a regular expression; when non-empty, only
+	// interfaces whose name matches it are collected.
+	InterfaceInclude string
+
+	// InterfaceExclude is a regular expression; interfaces whose name matches
+	// it are never collected. Exclude wins over include.
+	InterfaceExclude string
+
+	// Netns lists named network namespaces (as created by `ip netns add`,
+	// bound under <RootfsPath>/run/netns/) whose interfaces are collected in
+	// addition to the host's. Setting it adds a netns label to every series.
+	Netns []string
+
+	// SkipZeroDown omits interfaces whose operstate is down and whose rx
+	// and tx byte counters are both zero.
+	SkipZeroDown bool
+
+	// AppPrefixStrip lists prefixes stripped from compose project,
+	// container and Docker network names when deriving app names (the
+	// first match wins). Nil strips nothing; main defaults it to "ix-".
+	AppPrefixStrip []string
+
+	// ClassifyRules is the path of a rules file (see loadClassifyRules)
+	// whose regexp → instance_type rules override the built-in
+	// classification. The first rule matching an interface name wins.
+	ClassifyRules string
+
+	// AppInclude, when non-empty, lists the Docker/Podman apps (compose
+	// projects, as resolved by AppName) reported per interface. Container
+	// interfaces of other apps are summed into one app="other" series per
+	// instance type.
+	AppInclude []string
+
+	// InstanceTypeInclude, when non-empty, limits emitted interfaces to these
+	// instance types (e.g. "physical", "bond"). The type is only known after
+	// enrichment, so filtered interfaces are still fully resolved.
+	InstanceTypeInclude []string
+
+	// Counters, when non-empty, limits the per-interface counters emitted
+	// to these names (e.g. "rx_bytes", "tx_errors"); empty emits all.
+	Counters []string
+
+	// DockerCacheTTL is how long Docker container inspect results are reused
+	// across scrapes (0 = inspect every container on every collection).
+	DockerCacheTTL time.Duration
+
+	// DockerInspectConcurrency bounds parallel Docker container inspects.
+	DockerInspectConcurrency int
+
+	// IncusSocket is the path to the Incus (or LXD) API unix socket. When
+	// set, instances are resolved via the API instead of scanning cgroups.
+	IncusSocket string
+
+	// DockerMaxRetries is how many times a failed Docker list/inspect
+	// request is retried when the failure looks transient.
+	DockerMaxRetries int
+
+	// PodmanSocket is the path to a Podman Docker-compatible API socket.
+	// When set, Podman containers are mapped alongside Docker ones.
+	PodmanSocket string
+
+	// ContainerdSocket is the path to the containerd gRPC socket. When set,
+	// containerd tasks are mapped to veths if none of the Docker/Podman
+	// endpoints answered.
+	ContainerdSocket string
+
+	// StatsBackend selects where interface counters come from
+	// (StatsBackendProcfs by default, StatsBackendNetlink or
+	// StatsBackendSysfs).
+	StatsBackend string
+
+	// MACLabel adds a "mac" label with the interface's hardware address to
+	// every per-interface series.
+	MACLabel bool
+
+	// IPLabel adds an "ip" label with the container's address on the
+	// network owning each container veth (empty for other interfaces).
+	IPLabel bool
+
+	// ServiceLabel adds a "service" label with the Docker Compose service
+	// of the container owning each veth (empty when not set).
+	ServiceLabel bool
+
+	// DisableDocker, DisableVM, DisableIncus and DisableVLAN skip the
+	// corresponding enrichment source entirely (no API calls, commands or
+	// file reads), for hosts that never run that kind of workload.
+	DisableDocker bool
+	DisableVM     bool
+	DisableIncus  bool
+	DisableVLAN   bool
+
+	// DriverLabel adds a "driver" label with the kernel driver of physical
+	// NICs (e.g. ixgbe, mlx5_core; empty for other interfaces).
+	DriverLabel bool
+
+	// NetDriverLabel adds a "net_driver" label with the Docker/Podman
+	// network driver of bridges and macvlan/ipvlan parent interfaces
+	// (e.g. bridge, macvlan; empty for other interfaces).
+	NetDriverLabel bool
+
+	// UplinkLabel adds an "uplink" label with the physical NIC (or bond)
+	// behind the bridge an interface is attached to; bridges get their own
+	// uplink (empty for interfaces not on a bridge).
+	UplinkLabel bool
+
+	// ContainerPerspective swaps rx and tx on container interfaces (docker,
+	// podman, containerd, incus, k8s) so their counters describe the
+	// container's traffic instead of the host veth's, and adds a
+	// "perspective" label ("container" or "host") to every interface series.
+	ContainerPerspective bool
+
+	// ContainerTotals emits net_container_* counters summing all interfaces
+	// that belong to the same container.
+	ContainerTotals bool
+
+	// AggregateVLANs emits net_vlan_* counters summing all interfaces that
+	// resolve to the same VLAN ID.
+	AggregateVLANs bool
+
+	// Utilization emits net_interface_utilization_ratio, computed from the
+	// byte counter deltas between scrapes and the link speed. It keeps the
+	// previous scrape's counters in memory.
+	Utilization bool
+
+	// GaugeSnapshot also emits the byte counters as
+	// net_interface_{rx,tx}_bytes_current gauges, so interfaces that live
+	// for a single scrape (e.g. vnets during a VM migration) still leave a
+	// usable value.
+	GaugeSnapshot bool
+
+	// TopologyRefresh is how long the sysfs topology (ifindex, bridge
+	// membership, drivers) is reused before being re-read. It is rebuilt
+	// immediately when the interface set changes. Zero disables caching.
+	TopologyRefresh time.Duration
+
+	// DockerTLSCert, DockerTLSKey and DockerTLSCA configure mutual TLS for a
+	// tcp:// Docker endpoint. All empty means plain HTTP.
+	DockerTLSCert string
+	DockerTLSKey  string
+	DockerTLSCA   string
+
+	// ExecTimeout bounds each external command (midclt, virsh, wg, ethtool,
+	// ...). Zero or negative disables the deadline.
+	ExecTimeout time.Duration
+
+	// ExecPath lists extra directories searched for external commands
+	// before the standard PATH. In container mode they are paths inside
+	// RootfsPath.
+	ExecPath []string
+
+	// SNMPTargets lists switches (host or host:port) whose IF-MIB 64-bit
+	// octet counters are walked on each scrape and emitted as
+	// net_interface_{rx,tx}_bytes_total with instance set to the target.
+	// Setting it adds a "source" label ("snmp" or the stats backend) to
+	// every interface series.
+	SNMPTargets []string
+
+	// SNMPVersion is "2c" (default) or "3".
+	SNMPVersion string
+
+	// SNMPCommunity is the SNMPv2c community.
+	SNMPCommunity string
+
+	// SNMPUser, SNMPAuthProtocol ("SHA" or "MD5"), SNMPAuthPassword,
+	// SNMPPrivProtocol ("" or "AES") and SNMPPrivPassword configure SNMPv3.
+	// The security level follows from which passwords are set.
+	SNMPUser         string
+	SNMPAuthProtocol string
+	SNMPAuthPassword string
+	SNMPPrivProtocol string
+	SNMPPrivPassword string
+
+	// SNMPTimeout bounds the walk of one target (default 5s).
+	SNMPTimeout time.Duration
+
+	// MetricNamespace, when non-empty, is prepended to every metric name
+	// (e.g. "truenas" → truenas_net_interface_rx_bytes_total).
+	MetricNamespace string
 }
 
 // IsContainer returns true when the exporter seems to be running inside a container
@@ -17,3 +251,87 @@ type Options struct {
 func (o Options) IsContainer() bool {
 	return o.RootfsPath != "" && o.RootfsPath != "/"
 }
+
+// sysClassNetPath returns the path to /sys/class/net (respecting container paths).
+func (o Options) sysClassNetPath() string {
+	if o.IsContainer() {
+		return filepath.Join(o.RootfsPath, "sys", "class", "net")
+	}
+	return "/sys/class/net"
+}
+
+// defaultExecPath is the PATH commands are looked up in inside the chroot.
+const defaultExecPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// buildCommand creates an exec.Cmd that optionally uses chroot for container
+// mode. The process is killed if ctx is cancelled before it exits.
+func (o Options) buildCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if o.IsContainer() {
+		chrootArgs := append([]string{o.RootfsPath, name}, args...)
+		cmd := exec.CommandContext(ctx, "chroot", chrootArgs...)
+		if len(o.ExecPath) > 0 {
+			// chroot(8) resolves name with execvp, i.e. through PATH.
+			cmd.Env = append(os.Environ(), "PATH="+strings.Join(append(append([]string{}, o.ExecPath...), defaultExecPath), ":"))
+		}
+		return cmd
+	}
+	if path, err := lookPathIn(name, o.ExecPath); err == nil {
+		name = path
+	}
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// FindTool reports where buildCommand will find the external command name:
+// in ExecPath, then PATH (inside RootfsPath in container mode). The error
+// says where it looked when the tool is missing.
+func (o Options) FindTool(name string) (string, error) {
+	if !o.IsContainer() {
+		if path, err := lookPathIn(name, o.ExecPath); err == nil {
+			return path, nil
+		}
+		return exec.LookPath(name)
+	}
+	dirs := append(append([]string{}, o.ExecPath...), filepath.SplitList(defaultExecPath)...)
+	for _, dir := range dirs {
+		if isExecutable(filepath.Join(o.RootfsPath, dir, name)) {
+			return filepath.Join(dir, name), nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s under %s", name, strings.Join(dirs, ":"), o.RootfsPath)
+}
+
+// lookPathIn returns the first executable named name in dirs.
+func lookPathIn(name string, dirs []string) (string, error) {
+	for _, dir := range dirs {
+		if path := filepath.Join(dir, name); isExecutable(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s", name, strings.Join(dirs, ":"))
+}
+
+// isExecutable reports whether path is a regular file with an execute bit.
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular() && fi.Mode().Perm()&0o111 != 0
+}
+
+// execContext derives the context for one external command from parent,
+// applying ExecTimeout when it is positive.
+func (o Options) execContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if o.ExecTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, o.ExecTimeout)
+}
+
+// execError annotates a failed command's error. Commands killed by the
+// ExecTimeout deadline are logged as a warning so a wedged tool (e.g. midclt
+// on a busy system) is visible even though collection carries on without it.
+func execError(ctx context.Context, logger *slog.Logger, name string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.Warn("external command timed out", "command", name, "error", err)
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ovsDatapath is the kernel device Open vSwitch creates for its datapath.
+// Every port on an OVS bridge has it as its sysfs "master", so it must not
+// be mistaken for a Linux bridge.
+const ovsDatapath = "ovs-system"
+
+// buildOVSBridgeMap returns a mapping from port name → Open vSwitch bridge,
+// plus the set of OVS bridges themselves, using `ovs-vsctl list-br` and
+// `ovs-vsctl list-ports <bridge>`. It returns empty maps without running
+// anything when the host has no OVS datapath.
+func (c *NetworkCollector) buildOVSBridgeMap(sysNetPath string) (map[string]string, map[string]bool) {
+	ports := make(map[string]string)
+	bridges := make(map[string]bool)
+	if _, err := os.Stat(filepath.Join(sysNetPath, ovsDatapath)); err != nil {
+		return ports, bridges
+	}
+
+	names, err := c.runOVSVsctl("list-br")
+	if err != nil {
+		c.logger.Warn("cannot list Open vSwitch bridges", "error", err)
+		c.recordError("ovs")
+		return ports, bridges
+	}
+	for _, br := range names {
+		bridges[br] = true
+		members, err := c.runOVSVsctl("list-ports", br)
+		if err != nil {
+			c.logger.Warn("cannot list Open vSwitch bridge ports", "bridge", br, "error", err)
+			c.recordError("ovs")
+			continue
+		}
+		for _, port := range members {
+			ports[port] = br
+		}
+	}
+	return ports, bridges
+}
+
+// runOVSVsctl runs ovs-vsctl with args and returns its non-empty output
+// lines. list-br and list-ports print one name per line and have no JSON
+// form.
+func (c *NetworkCollector) runOVSVsctl(args ...string) ([]string, error) {
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "ovs-vsctl", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return nil, execError(ctx, c.logger, "ovs-vsctl", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,41 @@
+package collector
+
+// perspectiveNote is appended to the help of rx/tx metrics when
+// Options.ContainerPerspective is set.
+const perspectiveNote = ` On perspective="container" series rx and tx are swapped to the container's point of view: rx is traffic the container received, i.e. what its host-side veth transmitted.`
+
+// perspectiveHelp returns help, with perspectiveNote appended when
+// container-perspective counters are enabled.
+func perspectiveHelp(opts Options, help string) string {
+	if opts.ContainerPerspective {
+		return help + perspectiveNote
+	}
+	return help
+}
+
+// containerPerspective reports whether info's counters are reported from
+// the container's point of view rather than the host veth's.
+func (c *NetworkCollector) containerPerspective(info interfaceInfo) bool {
+	return c.opts.ContainerPerspective && containerInstanceTypes[info.InstanceType]
+}
+
+// perspectiveLabel returns the "perspective" label value for info.
+func (c *NetworkCollector) perspectiveLabel(info interfaceInfo) string {
+	if c.containerPerspective(info) {
+		return "container"
+	}
+	return "host"
+}
+
+// swapped returns s with each receive counter exchanged for its transmit
+// counterpart. Counters without a counterpart (rx_frame, rx_multicast,
+// tx_collisions, tx_carrier_errors) are left as they are.
+func (s interfaceStats) swapped() interfaceStats {
+	s.RxBytes, s.TxBytes = s.TxBytes, s.RxBytes
+	s.RxPackets, s.TxPackets = s.TxPackets, s.RxPackets
+	s.RxErrors, s.TxErrors = s.TxErrors, s.RxErrors
+	s.RxDropped, s.TxDropped = s.TxDropped, s.RxDropped
+	s.RxFifo, s.TxFifo = s.TxFifo, s.RxFifo
+	s.RxCompressed, s.TxCompressed = s.TxCompressed, s.RxCompressed
+	return s
+}
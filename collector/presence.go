@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// presenceTracker remembers which interfaces were emitted from the newest
+// snapshot, so an interface that disappears gets trailing
+// net_interface_present 0 samples instead of its series just going stale.
+// The vanished interfaces are tied to the snapshot that first missed them,
+// so every scrape served from that snapshot sees the 0, not only the first.
+type presenceTracker struct {
+	mu   sync.Mutex
+	at   time.Time           // countersAt of the newest snapshot emitted
+	prev map[string][]string // interface → label values in that snapshot
+	gone map[string][]string // interfaces missing from it that the snapshot before had
+}
+
+// emitPresence emits net_interface_present 1 for every interface in current
+// and 0 for interfaces that were present in the previous snapshot but are
+// missing from snap. A scrape served from an older snapshot than the newest
+// one already emitted gets no 0 samples.
+func (c *NetworkCollector) emitPresence(ch chan<- prometheus.Metric, snap *snapshot, current map[string][]string) {
+	c.presence.mu.Lock()
+	defer c.presence.mu.Unlock()
+
+	for _, labels := range current {
+		ch <- prometheus.MustNewConstMetric(c.present, prometheus.GaugeValue, 1, labels...)
+	}
+
+	t := &c.presence
+	switch {
+	case snap.countersAt.After(t.at):
+		t.gone = make(map[string][]string)
+		for iface, labels := range t.prev {
+			if _, ok := current[iface]; !ok {
+				t.gone[iface] = labels
+			}
+		}
+		t.at, t.prev = snap.countersAt, current
+	case snap.countersAt.Before(t.at):
+		return
+	}
+	for _, labels := range t.gone {
+		ch <- prometheus.MustNewConstMetric(c.present, prometheus.GaugeValue, 0, labels...)
+	}
+}
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// TestEmitPresenceSharedSnapshot checks that every scrape served from the
+// snapshot in which an interface vanished sees its 0 sample.
+func TestEmitPresenceSharedSnapshot(t *testing.T) {
+	c := testCollector(t, Options{})
+	base := time.Now()
+	snaps := []*snapshot{{countersAt: base}, {countersAt: base.Add(time.Second)}, {countersAt: base.Add(2 * time.Second)}}
+	labels := func(ifaces ...string) map[string][]string {
+		m := make(map[string][]string)
+		for _, iface := range ifaces {
+			info := interfaceInfo{Name: iface, Instance: iface, InstanceType: "physical"}
+			m[iface] = c.interfaceLabelValues(info)
+		}
+		return m
+	}
+	scrape := func(snap *snapshot, current map[string][]string) map[string]float64 {
+		ch := make(chan prometheus.Metric, 16)
+		c.emitPresence(ch, snap, current)
+		close(ch)
+		got := make(map[string]float64)
+		for m := range ch {
+			var pb dto.Metric
+			if err := m.Write(&pb); err != nil {
+				t.Fatal(err)
+			}
+			got[metricLabels(t, m)["interface"]] = pb.GetGauge().GetValue()
+		}
+		return got
+	}
+
+	tests := []struct {
+		name    string
+		snap    *snapshot
+		current map[string][]string
+		want    map[string]float64
+	}{
+		{"initial", snaps[0], labels("eno1", "veth1"), map[string]float64{"eno1": 1, "veth1": 1}},
+		{"veth1 removed", snaps[1], labels("eno1"), map[string]float64{"eno1": 1, "veth1": 0}},
+		{"second scraper, same snapshot", snaps[1], labels("eno1"), map[string]float64{"eno1": 1, "veth1": 0}},
+		{"next snapshot", snaps[2], labels("eno1"), map[string]float64{"eno1": 1}},
+		{"late scrape of an older snapshot", snaps[1], labels("eno1"), map[string]float64{"eno1": 1}},
+	}
+	for _, tt := range tests {
+		if got := scrape(tt.snap, tt.current); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: present = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := slices.Sorted(maps.Keys(c.presence.prev)); !slices.Equal(got, []string{"eno1"}) {
+		t.Errorf("tracked interfaces = %v, want [eno1]", got)
+	}
+}
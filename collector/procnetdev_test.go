@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestParseProcNetDevLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		iface   string
+		rxBytes uint64
+		txBytes uint64
+		txComp  uint64
+		wantErr bool
+	}{
+		{
+			name:  "normal",
+			line:  "  eth0: 1234 10 0 0 0 0 0 0 5678 20 0 0 0 0 0 0",
+			iface: "eth0", rxBytes: 1234, txBytes: 5678,
+		},
+		{
+			name:  "padded name",
+			line:  "    lo:       0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0",
+			iface: "lo",
+		},
+		{
+			name:  "long name without padding",
+			line:  "vethabcdef123456:1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16",
+			iface: "vethabcdef123456", rxBytes: 1, txBytes: 9, txComp: 16,
+		},
+		{
+			name:  "embedded colon",
+			line:  "eth0.0:0: 100 1 0 0 0 0 0 0 200 2 0 0 0 0 0 3",
+			iface: "eth0.0:0", rxBytes: 100, txBytes: 200, txComp: 3,
+		},
+		{
+			name:  "counter above 32 bits",
+			line:  " eno1: 18446744073709551615 1 0 0 0 0 0 0 4294967296 1 0 0 0 0 0 0",
+			iface: "eno1", rxBytes: 18446744073709551615, txBytes: 4294967296,
+		},
+		{name: "header", line: " face |bytes    packets errs drop fifo frame compressed multicast|bytes", wantErr: true},
+		{name: "no colon", line: "eth0 1 2 3", wantErr: true},
+		{name: "empty name", line: "   : 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16", wantErr: true},
+		{name: "too few fields", line: "eth0: 1 2 3 4 5 6 7 8", wantErr: true},
+		{name: "non-numeric", line: "eth0: 1 2 3 4 5 6 7 8 x 10 11 12 13 14 15 16", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iface, s, err := parseProcNetDevLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsed %q as %q, want error", tt.line, iface)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if iface != tt.iface || s.RxBytes != tt.rxBytes || s.TxBytes != tt.txBytes || s.TxCompressed != tt.txComp {
+				t.Errorf("got %q rx=%d tx=%d tx_compressed=%d, want %q rx=%d tx=%d tx_compressed=%d",
+					iface, s.RxBytes, s.TxBytes, s.TxCompressed, tt.iface, tt.rxBytes, tt.txBytes, tt.txComp)
+			}
+		})
+	}
+}
+
+// longLine is one byte longer than a proc scanner accepts.
+var longLine = strings.Repeat("x", maxProcLineBytes+1)
+
+func TestParseProcNetDevLongLine(t *testing.T) {
+	in := "Inter-|   Receive\n face |bytes\n" +
+		"  eth0: 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16\n" +
+		longLine + "\n" +
+		"  eth1: 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16\n"
+	stats, err := parseProcNetDev(strings.NewReader(in))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("error = %v, want bufio.ErrTooLong", err)
+	}
+	if !strings.Contains(err.Error(), "line longer than") {
+		t.Errorf("error %q does not explain the limit", err)
+	}
+	if _, ok := stats["eth0"]; !ok {
+		t.Error("lines before the over-long one were dropped")
+	}
+}
+
+func TestReadProcNetColumnsLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "if_inet6")
+	content := "fe800000000000000000000000000001 02 40 20 80 eth0\n" + longLine + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var rows int
+	err := readProcNetColumns(path, func([]string) { rows++ })
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), path) {
+		t.Errorf("error = %v, want bufio.ErrTooLong naming %s", err, path)
+	}
+	if rows != 1 {
+		t.Errorf("read %d rows before the over-long line, want 1", rows)
+	}
+
+	// A line just under the limit still parses.
+	ok := strings.Repeat("y", maxProcLineBytes-1)
+	if err := os.WriteFile(path, []byte(ok+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := readProcNetColumns(path, func([]string) {}); err != nil {
+		t.Errorf("line of %d bytes: %v", len(ok), err)
+	}
+
+	if err := readProcNetColumns(filepath.Join(t.TempDir(), "missing"), func([]string) {}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestContainerIfindexesLongLine(t *testing.T) {
+	procPath := t.TempDir()
+	netDir := filepath.Join(procPath, "42", "net")
+	if err := os.MkdirAll(netDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mcast := "1    lo              1     0     01005e000001\n7    eth0            1     0     01005e000001\n" + longLine + "\n"
+	if err := os.WriteFile(filepath.Join(netDir, "dev_mcast"), []byte(mcast), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// if_inet6 is absent (IPv4-only container), which is not an error.
+	got, err := containerIfindexes(procPath, 42)
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("error = %v, want bufio.ErrTooLong", err)
+	}
+	if !got[7] || got[1] {
+		t.Errorf("ifindexes = %v, want eth0 (7) only", got)
+	}
+}
+
+func TestBuildVLANMapLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := "VLAN Dev name    | VLAN ID\nName-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD\nvlan10         | 10  | eno1\n" + longLine + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := testCollector(t, Options{})
+	vlans := c.buildVLANMap(path)
+	if vlans["vlan10"] != (vlanInfo{ID: "10", Parent: "eno1"}) {
+		t.Errorf("vlans = %v, want vlan10 read before the over-long line", vlans)
+	}
+	if got := testutil.ToFloat64(c.scrapeErrors.WithLabelValues("vlan")); got != 1 {
+		t.Errorf("vlan scrape errors = %v, want 1", got)
+	}
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findQEMUProcesses scans /proc/*/cmdline for QEMU processes and returns the
+// guest name each was started with. It lets plain libvirt/KVM hosts resolve
+// VM names without midclt or virsh.
+func (c *NetworkCollector) findQEMUProcesses() ([]vmEntry, error) {
+	entries, err := os.ReadDir(c.opts.ProcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []vmEntry
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 0 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.opts.ProcPath, entry.Name(), "cmdline"))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		args := strings.Split(string(bytes.TrimRight(data, "\x00")), "\x00")
+		if !strings.Contains(filepath.Base(args[0]), "qemu") {
+			continue
+		}
+		if name := parseQEMUName(args[1:]); name != "" {
+			vms = append(vms, vmEntry{name: name, pid: pid})
+		}
+	}
+	return vms, nil
+}
+
+// parseQEMUName extracts the guest name from QEMU arguments. It accepts both
+// "-name guest=<vm>,debug-threads=on" (as written by libvirt) and the legacy
+// "-name <vm>" form. Returns "" if no name is given.
+func parseQEMUName(args []string) string {
+	for i, arg := range args {
+		var value string
+		switch {
+		case arg == "-name" || arg == "--name":
+			if i+1 >= len(args) {
+				return ""
+			}
+			value = args[i+1]
+		case strings.HasPrefix(arg, "-name="), strings.HasPrefix(arg, "--name="):
+			value = arg[strings.Index(arg, "=")+1:]
+		default:
+			continue
+		}
+
+		opts := splitQEMUOpts(value)
+		for _, opt := range opts {
+			if name, ok := strings.CutPrefix(opt, "guest="); ok {
+				return name
+			}
+		}
+		// Legacy form: the first option without a key is the name.
+		if len(opts) > 0 && !strings.Contains(opts[0], "=") {
+			return opts[0]
+		}
+		return ""
+	}
+	return ""
+}
+
+// splitQEMUOpts splits a QEMU option string on commas, treating ",," as an
+// escaped literal comma.
+func splitQEMUOpts(s string) []string {
+	var (
+		parts []string
+		cur   strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		if s[i] != ',' {
+			cur.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == ',' {
+			cur.WriteByte(',')
+			i++
+			continue
+		}
+		parts = append(parts, cur.String())
+		cur.Reset()
+	}
+	return append(parts, cur.String())
+}
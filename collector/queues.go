@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// interfaceQueues holds the number of rx/tx queues a NIC exposes under
+// /sys/class/net/<iface>/queues/.
+type interfaceQueues struct {
+	Rx int
+	Tx int
+}
+
+// readQueueCounts counts the rx-* and tx-* directories of iface. ok is false
+// when the interface has no queues directory.
+func readQueueCounts(sysNetPath, iface string) (q interfaceQueues, ok bool) {
+	entries, err := os.ReadDir(filepath.Join(sysNetPath, iface, "queues"))
+	if err != nil {
+		return q, false
+	}
+	for _, entry := range entries {
+		switch name := entry.Name(); {
+		case strings.HasPrefix(name, "rx-"):
+			q.Rx++
+		case strings.HasPrefix(name, "tx-"):
+			q.Tx++
+		}
+	}
+	return q, true
+}
@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// snapshot is a point-in-time view of interface counters together with the
+// enrichment metadata resolved for them. Snapshots are immutable once stored,
+// so Collect can read one while the background worker builds the next.
+type snapshot struct {
+	stats map[string]interfaceStats
+	info  map[string]interfaceInfo
+
+	// countersAt is when stats were read from procfs.
+	countersAt time.Time
+	// enrichedAt is when info was last rebuilt from Docker/VM/sysfs sources.
+	enrichedAt time.Time
+	// duration is the wall time spent gathering this snapshot.
+	duration time.Duration
+	// source is the path the counters were read from.
+	source string
+	// snmp holds the ports walked on each SNMP target, indexed like
+	// NetworkCollector.snmp.
+	snmp []map[uint32]*snmpPort
+}
+
+// Run refreshes the collector's snapshot in the background until ctx is
+// cancelled. Counters are re-read every Options.RefreshInterval while the
+// (slower) enrichment is only rebuilt once Options.EnrichmentTTL has elapsed
+// or a new interface appears.
+//
+// When RefreshInterval is zero, Run returns immediately and Collect gathers
+// everything on demand during the scrape.
+func (c *NetworkCollector) Run(ctx context.Context) {
+	if c.opts.RefreshInterval <= 0 {
+		return
+	}
+
+	c.logger.Info("background refresh enabled",
+		"refresh_interval", c.opts.RefreshInterval,
+		"enrichment_ttl", c.opts.EnrichmentTTL,
+	)
+
+	c.refresh()
+
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+// refresh reads fresh counters and, if the cached enrichment is stale,
+// rebuilds the interface metadata before publishing a new snapshot.
+func (c *NetworkCollector) refresh() {
+	start := time.Now()
+	stats, source, err := c.readStats()
+	if err != nil {
+		c.logger.Error("failed to read interface counters", "backend", c.opts.StatsBackend, "error", err)
+		c.recordError("procfs")
+		return
+	}
+	now := time.Now()
+
+	next := &snapshot{stats: stats, countersAt: now, source: source}
+	if prev := c.snap.Load(); prev != nil && !c.enrichmentStale(prev, stats, now) {
+		next.info = prev.info
+		next.enrichedAt = prev.enrichedAt
+		// Cached enrichment would otherwise hide a daemon that went away
+		// since; probing here keeps the scrape itself off the API.
+		c.probeRuntimes()
+	} else {
+		next.info = c.buildInterfaceInfo(stats, nil)
+		next.enrichedAt = now
+		c.logger.Debug("rebuilt interface enrichment", "count", len(next.info))
+	}
+	if len(c.snmp) > 0 {
+		next.snmp = c.walkSNMP()
+	}
+	next.duration = time.Since(start)
+
+	c.snap.Store(next)
+}
+
+// probeRuntimes refreshes the up state of every container runtime.
+func (c *NetworkCollector) probeRuntimes() {
+	for _, rt := range c.runtimes {
+		rt.up.Store(rt.client.Available(c.ctx))
+	}
+}
+
+// enrichmentStale reports whether the enrichment in prev must be rebuilt,
+// either because its TTL expired, because stats contains interfaces that
+// were not present when it was built, or because an interface was recreated
+// under the same name.
+func (c *NetworkCollector) enrichmentStale(prev *snapshot, stats map[string]interfaceStats, now time.Time) bool {
+	if now.Sub(prev.enrichedAt) >= c.opts.EnrichmentTTL {
+		return true
+	}
+	if c.checkInterfaceIdentity(stats) {
+		return true
+	}
+	for iface := range stats {
+		if _, ok := prev.info[iface]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// gatherGroup deduplicates on-demand collections so overlapping scrapes do
+// not each spawn their own Docker inspects and virsh calls.
+type gatherGroup struct {
+	mu       sync.Mutex
+	inflight *gatherCall
+	last     *snapshot // most recent result, reused for ScrapeDedupeWindow
+	lastAt   time.Time
+}
+
+// gatherCall is one on-demand collection that other scrapes can wait on.
+type gatherCall struct {
+	done chan struct{}
+	snap *snapshot
+}
+
+// currentSnapshot returns the snapshot Collect should emit. In background
+// mode it is the latest one published by Run (nil until the first refresh
+// completes); otherwise it is gathered synchronously, shared with any
+// scrape already waiting on a collection or finished within
+// Options.ScrapeDedupeWindow.
+func (c *NetworkCollector) currentSnapshot() *snapshot {
+	if c.opts.RefreshInterval > 0 {
+		return c.snap.Load()
+	}
+
+	g := &c.gather
+	g.mu.Lock()
+	if g.last != nil && time.Since(g.lastAt) < c.opts.ScrapeDedupeWindow {
+		snap := g.last
+		g.mu.Unlock()
+		return snap
+	}
+	if call := g.inflight; call != nil {
+		g.mu.Unlock()
+		<-call.done
+		return call.snap
+	}
+	call := &gatherCall{done: make(chan struct{})}
+	g.inflight = call
+	g.mu.Unlock()
+
+	call.snap = c.gatherSnapshot()
+
+	g.mu.Lock()
+	g.inflight = nil
+	if call.snap != nil {
+		g.last, g.lastAt = call.snap, time.Now()
+	}
+	g.mu.Unlock()
+	close(call.done)
+	return call.snap
+}
+
+// gatherSnapshot reads counters and resolves their enrichment on demand.
+func (c *NetworkCollector) gatherSnapshot() *snapshot {
+	start := time.Now()
+	stats, source, err := c.readStats()
+	if err != nil {
+		c.logger.Error("failed to read interface counters", "backend", c.opts.StatsBackend, "error", err)
+		c.recordError("procfs")
+		return nil
+	}
+	now := time.Now()
+	snap := &snapshot{
+		stats:      stats,
+		info:       c.buildInterfaceInfo(stats, nil),
+		countersAt: now,
+		enrichedAt: now,
+		source:     source,
+	}
+	if len(c.snmp) > 0 {
+		snap.snmp = c.walkSNMP()
+	}
+	snap.duration = time.Since(start)
+	return snap
+}
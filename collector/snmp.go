@@ -0,0 +1,421 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// IF-MIB columns walked on each SNMP target.
+var (
+	oidIfOperStatus  = []uint32{1, 3, 6, 1, 2, 1, 2, 2, 1, 8}
+	oidIfName        = []uint32{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 1}
+	oidIfHCInOctets  = []uint32{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6}
+	oidIfHCOutOctets = []uint32{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 10}
+)
+
+// ifOperStates maps IF-MIB ifOperStatus values to the operstate names
+// sysfs uses, so SNMP ports share the state label's values.
+var ifOperStates = map[int64]string{
+	1: "up", 2: "down", 3: "testing", 4: "unknown", 5: "dormant", 6: "notpresent", 7: "lowerlayerdown",
+}
+
+// snmpMaxRepetitions is the GETBULK max-repetitions used while walking.
+const snmpMaxRepetitions = 25
+
+// snmpVarbind is one variable binding of a response PDU.
+type snmpVarbind struct {
+	OID   []uint32
+	Type  byte
+	Value []byte
+}
+
+// snmpClient walks one SNMP agent over UDP with SNMPv2c or SNMPv3 (USM).
+// Calls are serialized; the SNMPv3 engine state is kept between them.
+type snmpClient struct {
+	target    string // as configured; used as the instance label
+	address   string // host:port
+	community string
+	usm       *usmUser // nil for SNMPv2c
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	reqID  int32
+	engine usmEngine
+}
+
+// newSNMPClient returns a client for target (host or host:port, port 161 by
+// default) configured from the SNMP fields of opts.
+func newSNMPClient(target string, opts Options) (*snmpClient, error) {
+	address := target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		address = net.JoinHostPort(target, "161")
+	}
+	c := &snmpClient{
+		target:    target,
+		address:   address,
+		community: opts.SNMPCommunity,
+		timeout:   opts.SNMPTimeout,
+		reqID:     rand.Int32N(1 << 30),
+	}
+	if c.timeout <= 0 {
+		c.timeout = 5 * time.Second
+	}
+	switch opts.SNMPVersion {
+	case "", "2c":
+	case "3":
+		usm, err := newUSMUser(opts)
+		if err != nil {
+			return nil, err
+		}
+		c.usm = usm
+	default:
+		return nil, fmt.Errorf("unknown SNMP version %q (want 2c or 3)", opts.SNMPVersion)
+	}
+	return c, nil
+}
+
+// snmpPort is one switch port's counters.
+type snmpPort struct {
+	name, state      string
+	rxBytes, txBytes uint64
+	hasRx, hasTx     bool
+}
+
+// ports walks the IF-MIB columns and returns the ports that have 64-bit
+// octet counters, keyed by ifIndex.
+func (s *snmpClient) ports(ctx context.Context) (map[uint32]*snmpPort, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", s.address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	ports := make(map[uint32]*snmpPort)
+	port := func(idx uint32) *snmpPort {
+		p, ok := ports[idx]
+		if !ok {
+			p = &snmpPort{name: strconv.FormatUint(uint64(idx), 10), state: "unknown"}
+			ports[idx] = p
+		}
+		return p
+	}
+	err = s.walkColumn(ctx, conn, oidIfHCInOctets, func(idx uint32, vb snmpVarbind) {
+		if v, err := berDecodeUint(vb.Value); err == nil && vb.Type == asnCounter64 {
+			p := port(idx)
+			p.rxBytes, p.hasRx = v, true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.walkColumn(ctx, conn, oidIfHCOutOctets, func(idx uint32, vb snmpVarbind) {
+		if v, err := berDecodeUint(vb.Value); err == nil && vb.Type == asnCounter64 {
+			p := port(idx)
+			p.txBytes, p.hasTx = v, true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) == 0 {
+		return ports, nil
+	}
+	err = s.walkColumn(ctx, conn, oidIfName, func(idx uint32, vb snmpVarbind) {
+		if p := ports[idx]; p != nil && vb.Type == asnOctetString && len(vb.Value) > 0 {
+			p.name = string(vb.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.walkColumn(ctx, conn, oidIfOperStatus, func(idx uint32, vb snmpVarbind) {
+		if v, err := berDecodeInt(vb.Value); err == nil && vb.Type == asnInteger && ports[idx] != nil && ifOperStates[v] != "" {
+			ports[idx].state = ifOperStates[v]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	uniquePortNames(ports)
+	return ports, nil
+}
+
+// uniquePortNames appends "#<ifIndex>" to the names of ports that share
+// their ifName with another port (stacked or virtual interfaces on some
+// switches), so every port gets its own series.
+func uniquePortNames(ports map[uint32]*snmpPort) {
+	count := make(map[string]int, len(ports))
+	for _, p := range ports {
+		count[p.name]++
+	}
+	for idx, p := range ports {
+		if count[p.name] > 1 {
+			p.name += "#" + strconv.FormatUint(uint64(idx), 10)
+		}
+	}
+}
+
+// walkColumn walks one table column and calls fn with the row index
+// (the OID's last arc) of each of its varbinds.
+func (s *snmpClient) walkColumn(ctx context.Context, conn net.Conn, column []uint32, fn func(idx uint32, vb snmpVarbind)) error {
+	vbs, err := s.walk(ctx, conn, column)
+	if err != nil {
+		return fmt.Errorf("walk %s: %w", oidString(column), err)
+	}
+	for _, vb := range vbs {
+		if len(vb.OID) == len(column)+1 {
+			fn(vb.OID[len(column)], vb)
+		}
+	}
+	return nil
+}
+
+// walk returns every varbind in the subtree rooted at root using GETBULK.
+func (s *snmpClient) walk(ctx context.Context, conn net.Conn, root []uint32) ([]snmpVarbind, error) {
+	var result []snmpVarbind
+	cur := root
+	for {
+		vbs, err := s.request(ctx, conn, asnGetBulkRequest, 0, snmpMaxRepetitions, cur)
+		if err != nil {
+			return nil, err
+		}
+		if len(vbs) == 0 {
+			return result, nil
+		}
+		for _, vb := range vbs {
+			if vb.Type == asnEndOfMibView || !oidHasPrefix(vb.OID, root) {
+				return result, nil
+			}
+			if oidCompare(vb.OID, cur) <= 0 {
+				return nil, fmt.Errorf("agent returned non-increasing OID %s", oidString(vb.OID))
+			}
+			result = append(result, vb)
+			cur = vb.OID
+		}
+	}
+}
+
+// request sends one PDU for oid and returns the response's varbinds.
+func (s *snmpClient) request(ctx context.Context, conn net.Conn, pduType byte, nonRepeaters, maxRepetitions int64, oid []uint32) ([]snmpVarbind, error) {
+	if s.usm != nil && s.engine.id == nil {
+		if err := s.discoverEngine(ctx, conn); err != nil {
+			return nil, fmt.Errorf("snmpv3 engine discovery: %w", err)
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		s.reqID++
+		reqID := s.reqID
+		pdu := encodePDU(pduType, reqID, nonRepeaters, maxRepetitions, [][]uint32{oid})
+
+		var msg []byte
+		if s.usm == nil {
+			msg = berTLV(asnSequence, berInt(1), berString([]byte(s.community)), pdu)
+		} else {
+			var err error
+			if msg, err = s.usm.wrap(&s.engine, reqID, pdu); err != nil {
+				return nil, err
+			}
+		}
+
+		respType, vbs, err := s.exchange(ctx, conn, msg, reqID)
+		var report *usmReportError
+		if errors.As(err, &report) && report.notInTimeWindow() && attempt == 0 {
+			continue // engine time was refreshed from the report; retry once
+		}
+		if err != nil {
+			return nil, err
+		}
+		if respType != asnGetResponse {
+			return nil, fmt.Errorf("unexpected PDU type 0x%02x", respType)
+		}
+		return vbs, nil
+	}
+}
+
+// exchange sends msg and waits for the response whose request ID is
+// reqID, discarding stale datagrams from earlier requests.
+func (s *snmpClient) exchange(ctx context.Context, conn net.Conn, msg []byte, reqID int32) (byte, []snmpVarbind, error) {
+	if _, err := conn.Write(msg); err != nil {
+		return 0, nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, nil, fmt.Errorf("no response from %s: %w", s.address, ctx.Err())
+			}
+			return 0, nil, err
+		}
+		pdu, err := s.unwrap(buf[:n])
+		if err != nil {
+			return 0, nil, err
+		}
+		respType, respID, errStatus, errIndex, vbs, err := decodePDU(pdu)
+		if err != nil {
+			return 0, nil, err
+		}
+		if respID != reqID && respType != asnReport {
+			continue
+		}
+		if respType == asnReport {
+			return 0, nil, newUSMReportError(vbs)
+		}
+		if errStatus != 0 {
+			return 0, nil, fmt.Errorf("agent error-status %d at index %d", errStatus, errIndex)
+		}
+		return respType, vbs, nil
+	}
+}
+
+// unwrap returns the PDU of a response message, checking the community
+// for SNMPv2c and authenticating/decrypting it for SNMPv3.
+func (s *snmpClient) unwrap(msg []byte) ([]byte, error) {
+	if s.usm != nil {
+		return s.usm.unwrap(&s.engine, msg)
+	}
+	body, _, err := berExpect(msg, asnSequence)
+	if err != nil {
+		return nil, err
+	}
+	version, body, err := berReadInt(body)
+	if err != nil {
+		return nil, err
+	}
+	if version != 1 {
+		return nil, fmt.Errorf("unexpected SNMP version %d in response", version)
+	}
+	if _, body, err = berExpect(body, asnOctetString); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// encodePDU builds a request PDU with one NULL-valued varbind per oid. For
+// GETBULK the two integers are non-repeaters and max-repetitions, otherwise
+// error-status and error-index (both 0).
+func encodePDU(pduType byte, reqID int32, a, b int64, oids [][]uint32) []byte {
+	var vbs []byte
+	for _, oid := range oids {
+		vbs = append(vbs, berTLV(asnSequence, berOID(oid), berTLV(asnNull))...)
+	}
+	return berTLV(pduType, berInt(int64(reqID)), berInt(a), berInt(b), berTLV(asnSequence, vbs))
+}
+
+// decodePDU parses a response or report PDU.
+func decodePDU(b []byte) (pduType byte, reqID int32, errStatus, errIndex int64, vbs []snmpVarbind, err error) {
+	pduType, body, _, err := berRead(b)
+	if err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	id, body, err := berReadInt(body)
+	if err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	if errStatus, body, err = berReadInt(body); err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	if errIndex, body, err = berReadInt(body); err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	list, _, err := berExpect(body, asnSequence)
+	if err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	for len(list) > 0 {
+		var vb []byte
+		if vb, list, err = berExpect(list, asnSequence); err != nil {
+			return 0, 0, 0, 0, nil, err
+		}
+		rawOID, rest, err := berExpect(vb, asnOID)
+		if err != nil {
+			return 0, 0, 0, 0, nil, err
+		}
+		oid, err := berDecodeOID(rawOID)
+		if err != nil {
+			return 0, 0, 0, 0, nil, err
+		}
+		tag, value, _, err := berRead(rest)
+		if err != nil {
+			return 0, 0, 0, 0, nil, err
+		}
+		vbs = append(vbs, snmpVarbind{OID: oid, Type: tag, Value: value})
+	}
+	return pduType, int32(id), errStatus, errIndex, vbs, nil
+}
+
+// walkSNMP walks every SNMP target in parallel and returns their ports,
+// indexed like c.snmp. A target that fails has a nil entry. Nothing is
+// walked when the instance type filter excludes physical ports.
+func (c *NetworkCollector) walkSNMP() []map[uint32]*snmpPort {
+	if !c.instanceTypeAllowed("physical") {
+		return nil
+	}
+	start := time.Now()
+	results := make([]map[uint32]*snmpPort, len(c.snmp))
+	var wg sync.WaitGroup
+	for i, client := range c.snmp {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ports, err := client.ports(c.ctx)
+			if err != nil {
+				c.warnFailure("snmp "+client.target, "SNMP walk failed", "target", client.target, "error", err)
+				c.recordError("snmp")
+				return
+			}
+			c.clearFailure("snmp "+client.target, "SNMP walk recovered", "target", client.target)
+			results[i] = ports
+		}()
+	}
+	wg.Wait()
+	c.observePhase("snmp", start)
+	return results
+}
+
+// emitSNMP emits the byte counters of the switch ports walked into snap,
+// subject to the same interface name filters as local interfaces.
+func (c *NetworkCollector) emitSNMP(ch chan<- prometheus.Metric, snap *snapshot) {
+	var rx, tx bool
+	for _, ctr := range c.counters {
+		rx = rx || ctr.desc == c.rxBytes
+		tx = tx || ctr.desc == c.txBytes
+	}
+	for i, ports := range snap.snmp {
+		for _, p := range ports {
+			if !c.interfaceAllowed(p.name) {
+				continue
+			}
+			labels := c.interfaceLabelValues(interfaceInfo{
+				Name:         p.name,
+				Instance:     c.snmp[i].target,
+				InstanceType: "physical",
+				App:          "system",
+				State:        p.state,
+				Source:       "snmp",
+			})
+			if rx && p.hasRx {
+				ch <- prometheus.MustNewConstMetric(c.rxBytes, prometheus.CounterValue, float64(p.rxBytes), labels...)
+			}
+			if tx && p.hasTx {
+				ch <- prometheus.MustNewConstMetric(c.txBytes, prometheus.CounterValue, float64(p.txBytes), labels...)
+			}
+		}
+	}
+}
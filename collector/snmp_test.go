@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestUniquePortNames(t *testing.T) {
+	ports := map[uint32]*snmpPort{
+		1:     {name: "Gi1/0/1"},
+		2:     {name: "Gi1/0/2"},
+		10101: {name: "Gi1/0/1"},
+		7:     {name: "7"},
+	}
+	uniquePortNames(ports)
+	want := map[uint32]string{1: "Gi1/0/1#1", 2: "Gi1/0/2", 10101: "Gi1/0/1#10101", 7: "7"}
+	for idx, name := range want {
+		if got := ports[idx].name; got != name {
+			t.Errorf("port %d: name %q, want %q", idx, got, name)
+		}
+	}
+}
+
+func TestEmitSNMPFilters(t *testing.T) {
+	snap := &snapshot{snmp: []map[uint32]*snmpPort{{
+		1: {name: "Gi1/0/1", state: "up", rxBytes: 10, txBytes: 20, hasRx: true, hasTx: true},
+		2: {name: "Gi1/0/2", state: "down", rxBytes: 30, hasRx: true},
+		3: {name: "Vlan1", state: "up", rxBytes: 40, txBytes: 50, hasRx: true, hasTx: true},
+	}}}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string // interface label of each emitted series
+	}{
+		{"all", Options{}, []string{"Gi1/0/1", "Gi1/0/1", "Gi1/0/2", "Vlan1", "Vlan1"}},
+		{"include", Options{InterfaceInclude: "^Gi"}, []string{"Gi1/0/1", "Gi1/0/1", "Gi1/0/2"}},
+		{"exclude", Options{InterfaceExclude: "^Vlan"}, []string{"Gi1/0/1", "Gi1/0/1", "Gi1/0/2"}},
+	}
+	for _, tt := range tests {
+		tt.opts.SNMPTargets = []string{"192.0.2.1"}
+		c := testCollector(t, tt.opts)
+		ch := make(chan prometheus.Metric, 16)
+		c.emitSNMP(ch, snap)
+		close(ch)
+		var got []string
+		for m := range ch {
+			got = append(got, metricLabels(t, m)["interface"])
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: emitted %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWalkSNMPSkippedByTypeFilter(t *testing.T) {
+	c := testCollector(t, Options{SNMPTargets: []string{"192.0.2.1"}, InstanceTypeInclude: []string{"docker"}})
+	if got := c.walkSNMP(); got != nil {
+		t.Errorf("walkSNMP = %v, want no walk when physical ports are filtered out", got)
+	}
+}
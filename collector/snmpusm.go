@@ -0,0 +1,373 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net"
+	"strings"
+	"time"
+)
+
+// SNMPv3 message constants (RFC 3412, RFC 3414).
+const (
+	snmpMaxMessageSize = 65507
+	usmSecurityModel   = 3
+	usmFlagAuth        = 0x01
+	usmFlagPriv        = 0x02
+	usmFlagReportable  = 0x04
+	usmAuthParamsLen   = 12 // HMAC-MD5-96 and HMAC-SHA-96
+	usmPrivParamsLen   = 8
+	usmMinPasswordLen  = 8
+)
+
+// usmStats is the usmStats subtree whose counters agents return in reports
+// to explain why a request was rejected.
+var usmStats = []uint32{1, 3, 6, 1, 6, 3, 15, 1, 1}
+
+// usmStatsNames names the usmStats counters by their arc under usmStats.
+var usmStatsNames = map[uint32]string{
+	1: "usmStatsUnsupportedSecLevels",
+	2: "usmStatsNotInTimeWindows",
+	3: "usmStatsUnknownUserNames",
+	4: "usmStatsUnknownEngineIDs",
+	5: "usmStatsWrongDigests",
+	6: "usmStatsDecryptionErrors",
+}
+
+// usmEngine is the authoritative engine state learned from an agent.
+type usmEngine struct {
+	id    []byte
+	boots int64
+	time  int64
+	at    time.Time // when time was learned
+}
+
+// now returns the engine's boots and its current estimated time.
+func (e *usmEngine) now() (boots, t int64) {
+	return e.boots, e.time + int64(time.Since(e.at)/time.Second)
+}
+
+// usmUser holds an SNMPv3 user's credentials and the keys localized to the
+// agent's engine ID. Supported: noAuthNoPriv, authNoPriv and authPriv with
+// HMAC-MD5-96 or HMAC-SHA-96 authentication and AES-128 (CFB) privacy.
+type usmUser struct {
+	name         string
+	authHash     func() hash.Hash // nil for noAuthNoPriv
+	authPassword string
+	priv         bool
+	privPassword string
+
+	keyEngine []byte // engine ID authKey and privKey are localized to
+	authKey   []byte
+	privKey   []byte
+	salt      uint64
+}
+
+// newUSMUser validates the SNMPv3 fields of opts.
+func newUSMUser(opts Options) (*usmUser, error) {
+	if opts.SNMPUser == "" {
+		return nil, errors.New("SNMPv3 requires a user name")
+	}
+	u := &usmUser{name: opts.SNMPUser, authPassword: opts.SNMPAuthPassword, privPassword: opts.SNMPPrivPassword}
+	if u.authPassword != "" {
+		switch strings.ToUpper(opts.SNMPAuthProtocol) {
+		case "", "SHA":
+			u.authHash = sha1.New
+		case "MD5":
+			u.authHash = md5.New
+		default:
+			return nil, fmt.Errorf("unknown SNMPv3 auth protocol %q (want SHA or MD5)", opts.SNMPAuthProtocol)
+		}
+		if len(u.authPassword) < usmMinPasswordLen {
+			return nil, fmt.Errorf("SNMPv3 auth password must be at least %d characters", usmMinPasswordLen)
+		}
+	}
+	switch strings.ToUpper(opts.SNMPPrivProtocol) {
+	case "":
+	case "AES":
+		if u.authHash == nil {
+			return nil, errors.New("SNMPv3 privacy requires an auth password")
+		}
+		if len(u.privPassword) < usmMinPasswordLen {
+			return nil, fmt.Errorf("SNMPv3 priv password must be at least %d characters", usmMinPasswordLen)
+		}
+		u.priv = true
+	default:
+		return nil, fmt.Errorf("unknown SNMPv3 priv protocol %q (want AES)", opts.SNMPPrivProtocol)
+	}
+	return u, nil
+}
+
+// localize derives the user's keys for engineID, once per engine.
+func (u *usmUser) localize(engineID []byte) {
+	if u.authHash == nil || (u.authKey != nil && bytes.Equal(u.keyEngine, engineID)) {
+		return
+	}
+	u.keyEngine = append([]byte(nil), engineID...)
+	u.authKey = usmLocalizedKey(u.authHash, u.authPassword, engineID)
+	if u.priv {
+		u.privKey = usmLocalizedKey(u.authHash, u.privPassword, engineID)[:16]
+	}
+}
+
+// usmLocalizedKey implements the password-to-key algorithm of RFC 3414
+// A.2: the password is repeated to fill 1MB, hashed, and the digest is
+// hashed again around engineID.
+func usmLocalizedKey(newHash func() hash.Hash, password string, engineID []byte) []byte {
+	h := newHash()
+	buf := make([]byte, 64)
+	for i, n := 0, 0; n < 1<<20; n += len(buf) {
+		for j := range buf {
+			buf[j] = password[i%len(password)]
+			i++
+		}
+		h.Write(buf)
+	}
+	ku := h.Sum(nil)
+	h.Reset()
+	h.Write(ku)
+	h.Write(engineID)
+	h.Write(ku)
+	return h.Sum(nil)
+}
+
+// usmIV builds the AES initialization vector of RFC 3826: engine boots,
+// engine time and the 64-bit salt sent as msgPrivacyParameters.
+func usmIV(boots, t int64, salt []byte) []byte {
+	iv := make([]byte, 0, aes.BlockSize)
+	iv = binary.BigEndian.AppendUint32(iv, uint32(boots))
+	iv = binary.BigEndian.AppendUint32(iv, uint32(t))
+	return append(iv, salt...)
+}
+
+// wrap builds an SNMPv3 message carrying pdu at the user's security level.
+func (u *usmUser) wrap(e *usmEngine, msgID int32, pdu []byte) ([]byte, error) {
+	u.localize(e.id)
+	boots, t := e.now()
+
+	flags := byte(usmFlagReportable)
+	msgData := berTLV(asnSequence, berString(e.id), berString(nil), pdu)
+	var authParams, privParams []byte
+	if u.authHash != nil {
+		flags |= usmFlagAuth
+		authParams = make([]byte, usmAuthParamsLen)
+	}
+	if u.priv {
+		flags |= usmFlagPriv
+		u.salt++
+		privParams = binary.BigEndian.AppendUint64(nil, u.salt)
+		block, err := aes.NewCipher(u.privKey)
+		if err != nil {
+			return nil, err
+		}
+		enc := make([]byte, len(msgData))
+		// RFC 3826 mandates CFB; integrity comes from the HMAC.
+		cipher.NewCFBEncrypter(block, usmIV(boots, t, privParams)).XORKeyStream(enc, msgData)
+		msgData = berString(enc)
+	}
+
+	secFields := [][]byte{berString(e.id), berInt(boots), berInt(t), berString([]byte(u.name)), berString(authParams), berString(privParams)}
+	secParams := berTLV(asnSequence, secFields...)
+	version := berInt(3)
+	global := berTLV(asnSequence, berInt(int64(msgID)), berInt(snmpMaxMessageSize), berString([]byte{flags}), berInt(usmSecurityModel))
+	msg := berTLV(asnSequence, version, global, berString(secParams), msgData)
+	if u.authHash == nil {
+		return msg, nil
+	}
+
+	// Locate msgAuthenticationParameters and sign the whole message with
+	// it zeroed (RFC 3414 6.3.1).
+	secLen := 0
+	for _, f := range secFields {
+		secLen += len(f)
+	}
+	off := len(msg) - (len(version) + len(global) + len(berString(secParams)) + len(msgData))
+	off += len(version) + len(global) + berHeaderLen(len(secParams)) + len(secParams) - secLen
+	for _, f := range secFields[:4] {
+		off += len(f)
+	}
+	off += berHeaderLen(usmAuthParamsLen)
+	mac := hmac.New(u.authHash, u.authKey)
+	mac.Write(msg)
+	copy(msg[off:off+usmAuthParamsLen], mac.Sum(nil))
+	return msg, nil
+}
+
+// unwrap authenticates and decrypts an SNMPv3 response as its flags
+// require, records the agent's engine state, and returns its PDU.
+func (u *usmUser) unwrap(e *usmEngine, msg []byte) ([]byte, error) {
+	body, _, err := berExpect(msg, asnSequence)
+	if err != nil {
+		return nil, err
+	}
+	version, body, err := berReadInt(body)
+	if err != nil {
+		return nil, err
+	}
+	if version != 3 {
+		return nil, fmt.Errorf("unexpected SNMP version %d in response", version)
+	}
+	global, body, err := berExpect(body, asnSequence)
+	if err != nil {
+		return nil, err
+	}
+	if _, global, err = berReadInt(global); err != nil { // msgID
+		return nil, err
+	}
+	if _, global, err = berReadInt(global); err != nil { // msgMaxSize
+		return nil, err
+	}
+	flags, _, err := berExpect(global, asnOctetString)
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) != 1 {
+		return nil, errors.New("invalid msgFlags")
+	}
+
+	secRaw, body, err := berExpect(body, asnOctetString)
+	if err != nil {
+		return nil, err
+	}
+	sec, _, err := berExpect(secRaw, asnSequence)
+	if err != nil {
+		return nil, err
+	}
+	engineID, sec, err := berExpect(sec, asnOctetString)
+	if err != nil {
+		return nil, err
+	}
+	boots, sec, err := berReadInt(sec)
+	if err != nil {
+		return nil, err
+	}
+	t, sec, err := berReadInt(sec)
+	if err != nil {
+		return nil, err
+	}
+	if _, sec, err = berExpect(sec, asnOctetString); err != nil { // user name
+		return nil, err
+	}
+	authParams, sec, err := berExpect(sec, asnOctetString)
+	if err != nil {
+		return nil, err
+	}
+	privParams, _, err := berExpect(sec, asnOctetString)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags[0]&usmFlagAuth != 0 {
+		if u.authHash == nil || u.authKey == nil || len(authParams) != usmAuthParamsLen {
+			return nil, errors.New("cannot authenticate response")
+		}
+		// authParams is a subslice of msg; find its offset to zero it.
+		off := cap(msg) - cap(authParams)
+		check := append([]byte(nil), msg...)
+		clear(check[off : off+usmAuthParamsLen])
+		mac := hmac.New(u.authHash, u.authKey)
+		mac.Write(check)
+		if !hmac.Equal(mac.Sum(nil)[:usmAuthParamsLen], authParams) {
+			return nil, errors.New("response failed authentication")
+		}
+	}
+	if e.id == nil || bytes.Equal(e.id, engineID) {
+		e.id = append([]byte(nil), engineID...)
+		e.boots, e.time, e.at = boots, t, time.Now()
+	}
+
+	scoped := body
+	if flags[0]&usmFlagPriv != 0 {
+		enc, _, err := berExpect(body, asnOctetString)
+		if err != nil {
+			return nil, err
+		}
+		if !u.priv || len(privParams) != usmPrivParamsLen {
+			return nil, errors.New("cannot decrypt response")
+		}
+		block, err := aes.NewCipher(u.privKey)
+		if err != nil {
+			return nil, err
+		}
+		scoped = make([]byte, len(enc))
+		cipher.NewCFBDecrypter(block, usmIV(boots, t, privParams)).XORKeyStream(scoped, enc)
+	}
+	pdu, _, err := berExpect(scoped, asnSequence)
+	if err != nil {
+		return nil, err
+	}
+	if _, pdu, err = berExpect(pdu, asnOctetString); err != nil { // contextEngineID
+		return nil, err
+	}
+	if _, pdu, err = berExpect(pdu, asnOctetString); err != nil { // contextName
+		return nil, err
+	}
+	return pdu, nil
+}
+
+// discoverEngine learns the agent's engine ID, boots and time by sending an
+// unauthenticated, empty request the agent answers with a report
+// (RFC 3414 4).
+func (s *snmpClient) discoverEngine(ctx context.Context, conn net.Conn) error {
+	s.reqID++
+	pdu := encodePDU(asnGetRequest, s.reqID, 0, 0, nil)
+	secParams := berTLV(asnSequence, berString(nil), berInt(0), berInt(0), berString(nil), berString(nil), berString(nil))
+	global := berTLV(asnSequence, berInt(int64(s.reqID)), berInt(snmpMaxMessageSize), berString([]byte{usmFlagReportable}), berInt(usmSecurityModel))
+	msg := berTLV(asnSequence, berInt(3), global, berString(secParams), berTLV(asnSequence, berString(nil), berString(nil), pdu))
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("no response from %s: %w", s.address, ctx.Err())
+		}
+		return err
+	}
+	s.engine = usmEngine{}
+	if _, err := s.usm.unwrap(&s.engine, buf[:n]); err != nil {
+		return err
+	}
+	if len(s.engine.id) == 0 {
+		return errors.New("agent reported an empty engine ID")
+	}
+	return nil
+}
+
+// usmReportError is a report PDU returned instead of a response.
+type usmReportError struct {
+	oid []uint32
+}
+
+// newUSMReportError wraps the first varbind of a report PDU.
+func newUSMReportError(vbs []snmpVarbind) *usmReportError {
+	if len(vbs) == 0 {
+		return &usmReportError{}
+	}
+	return &usmReportError{oid: vbs[0].OID}
+}
+
+func (e *usmReportError) Error() string {
+	if len(e.oid) > len(usmStats) && oidHasPrefix(e.oid, usmStats) {
+		if name := usmStatsNames[e.oid[len(usmStats)]]; name != "" {
+			return fmt.Sprintf("agent sent report %s", name)
+		}
+	}
+	return fmt.Sprintf("agent sent report %s", oidString(e.oid))
+}
+
+// notInTimeWindow reports whether the agent rejected the request's engine
+// time; the report carries the correct one.
+func (e *usmReportError) notInTimeWindow() bool {
+	return len(e.oid) > len(usmStats) && oidHasPrefix(e.oid, usmStats) && e.oid[len(usmStats)] == 2
+}
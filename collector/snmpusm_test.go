@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+// rfc3414EngineID is the engine ID of the RFC 3414 A.3 key localization
+// examples.
+var rfc3414EngineID = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2}
+
+func TestUSMLocalizedKey(t *testing.T) {
+	// RFC 3414 A.3.1 (MD5) and A.3.2 (SHA), password "maplesyrup".
+	tests := []struct {
+		name    string
+		newHash func() hash.Hash
+		want    string
+	}{
+		{"MD5", md5.New, "526f5eed9fcce26f8964c2930787d82b"},
+		{"SHA", sha1.New, "6695febc9288e36282235fc7151f128497b38f3f"},
+	}
+	for _, tt := range tests {
+		got := usmLocalizedKey(tt.newHash, "maplesyrup", rfc3414EngineID)
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s: localized key %x, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUSMIV(t *testing.T) {
+	// RFC 3826 3.1.2.1: boots, time and salt, all big-endian.
+	salt := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	got := usmIV(0x01020304, 0x0a0b0c0d, salt)
+	if want := "010203040a0b0c0d0102030405060708"; hex.EncodeToString(got) != want {
+		t.Errorf("usmIV = %x, want %s", got, want)
+	}
+}
+
+func testUSMUser(t *testing.T) *usmUser {
+	t.Helper()
+	u, err := newUSMUser(Options{
+		SNMPUser:         "monitor",
+		SNMPAuthPassword: "maplesyrup",
+		SNMPPrivProtocol: "AES",
+		SNMPPrivPassword: "maplesyrup",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// TestUSMUnwrapAuthPriv decrypts an authPriv response assembled here
+// independently of wrap: the scoped PDU is encrypted with the RFC 3414
+// A.3.2 key and signed by locating the authentication parameters by value.
+func TestUSMUnwrapAuthPriv(t *testing.T) {
+	authKey, _ := hex.DecodeString("6695febc9288e36282235fc7151f128497b38f3f")
+	privKey := authKey[:16] // same password, so the same localized key
+	const boots, engineTime = 5, 100
+	salt := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+
+	// GetResponse, request-id 7, ifHCInOctets.1 = Counter64 0x0102030405060708.
+	pdu := berTLV(asnGetResponse, berInt(7), berInt(0), berInt(0),
+		berTLV(asnSequence, berTLV(asnSequence, berOID(append(oidIfHCInOctets, 1)), berTLV(asnCounter64, []byte{1, 2, 3, 4, 5, 6, 7, 8}))))
+	scoped := berTLV(asnSequence, berString(rfc3414EngineID), berString(nil), pdu)
+
+	block, err := aes.NewCipher(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := append([]byte{0, 0, 0, boots, 0, 0, 0, engineTime}, salt...)
+	enc := make([]byte, len(scoped))
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(enc, scoped)
+
+	placeholder := bytes.Repeat([]byte{0xee}, usmAuthParamsLen)
+	secParams := berTLV(asnSequence, berString(rfc3414EngineID), berInt(boots), berInt(engineTime),
+		berString([]byte("monitor")), berString(placeholder), berString(salt))
+	global := berTLV(asnSequence, berInt(42), berInt(snmpMaxMessageSize), berString([]byte{usmFlagAuth | usmFlagPriv}), berInt(usmSecurityModel))
+	msg := berTLV(asnSequence, berInt(3), global, berString(secParams), berString(enc))
+	off := bytes.Index(msg, placeholder)
+	clear(msg[off : off+usmAuthParamsLen])
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(msg)
+	copy(msg[off:], mac.Sum(nil)[:usmAuthParamsLen])
+
+	u := testUSMUser(t)
+	u.localize(rfc3414EngineID)
+	var e usmEngine
+	got, err := u.unwrap(&e, msg)
+	if err != nil {
+		t.Fatalf("unwrap: %v", err)
+	}
+	if !bytes.Equal(got, pdu) {
+		t.Fatalf("unwrap = %x, want %x", got, pdu)
+	}
+	if !bytes.Equal(e.id, rfc3414EngineID) || e.boots != boots || e.time != engineTime {
+		t.Errorf("engine state = %x/%d/%d, want %x/%d/%d", e.id, e.boots, e.time, rfc3414EngineID, boots, engineTime)
+	}
+
+	_, reqID, _, _, vbs, err := decodePDU(got)
+	if err != nil || reqID != 7 || len(vbs) != 1 {
+		t.Fatalf("decodePDU = %d, %v, %v", reqID, vbs, err)
+	}
+	if v, err := berDecodeUint(vbs[0].Value); err != nil || vbs[0].Type != asnCounter64 || v != 0x0102030405060708 {
+		t.Errorf("varbind = 0x%02x %d, %v", vbs[0].Type, v, err)
+	}
+
+	tampered := bytes.Clone(msg)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := u.unwrap(&usmEngine{}, tampered); err == nil {
+		t.Error("unwrap accepted a tampered message")
+	}
+}
+
+func TestUSMWrapUnwrap(t *testing.T) {
+	for _, opts := range []Options{
+		{SNMPUser: "monitor"},
+		{SNMPUser: "monitor", SNMPAuthProtocol: "MD5", SNMPAuthPassword: "maplesyrup"},
+		{SNMPUser: "monitor", SNMPAuthPassword: "maplesyrup", SNMPPrivProtocol: "AES", SNMPPrivPassword: "maplesyrup"},
+	} {
+		u, err := newUSMUser(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := &usmEngine{id: rfc3414EngineID, boots: 3, time: 1000}
+		pdu := encodePDU(asnGetRequest, 9, 0, 0, [][]uint32{oidIfName})
+		msg, err := u.wrap(e, 1, pdu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A request has the same layout as a response, so the user can
+		// verify and decrypt its own message.
+		got, err := u.unwrap(&usmEngine{}, msg)
+		if err != nil {
+			t.Errorf("%+v: unwrap(wrap) = %v", opts, err)
+			continue
+		}
+		if !bytes.Equal(got, pdu) {
+			t.Errorf("%+v: unwrap(wrap) = %x, want %x", opts, got, pdu)
+		}
+	}
+}
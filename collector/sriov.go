@@ -0,0 +1,18 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// readSRIOVPhysFn returns the physical function (PF) netdev of iface when
+// iface is an SR-IOV virtual function, or "" otherwise. A VF's PCI device
+// has a physfn symlink to its PF's device, whose net/ directory names the
+// PF interface.
+func readSRIOVPhysFn(sysNetPath, iface string) string {
+	entries, err := os.ReadDir(filepath.Join(sysNetPath, iface, "device", "physfn", "net"))
+	if err != nil || len(entries) != 1 {
+		return ""
+	}
+	return entries[0].Name()
+}
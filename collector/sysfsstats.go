@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// readSysfsStats reads every interface's counters from
+// /sys/class/net/<iface>/statistics/, aggregating the error classes exactly
+// as the kernel does for /proc/net/dev so all backends produce identical
+// series. Counter files that are missing or unreadable count as 0.
+func readSysfsStats(sysNetPath string) (map[string]interfaceStats, error) {
+	entries, err := os.ReadDir(sysNetPath)
+	if err != nil {
+		return nil, fmt.Errorf("sysfs statistics: %w", err)
+	}
+
+	result := make(map[string]interfaceStats, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(sysNetPath, entry.Name(), "statistics")
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		f := func(name string) uint64 {
+			v, _ := strconv.ParseUint(readFileString(filepath.Join(dir, name)), 10, 64)
+			return v
+		}
+		result[entry.Name()] = interfaceStats{
+			RxBytes:      f("rx_bytes"),
+			RxPackets:    f("rx_packets"),
+			RxErrors:     f("rx_errors"),
+			RxDropped:    f("rx_dropped") + f("rx_missed_errors"),
+			RxFifo:       f("rx_fifo_errors"),
+			RxFrame:      f("rx_length_errors") + f("rx_over_errors") + f("rx_crc_errors") + f("rx_frame_errors"),
+			RxCompressed: f("rx_compressed"),
+			RxMulticast:  f("multicast"),
+			TxBytes:      f("tx_bytes"),
+			TxPackets:    f("tx_packets"),
+			TxErrors:     f("tx_errors"),
+			TxDropped:    f("tx_dropped"),
+			TxFifo:       f("tx_fifo_errors"),
+			TxColls:      f("collisions"),
+			TxCarrier:    f("tx_carrier_errors") + f("tx_aborted_errors") + f("tx_window_errors") + f("tx_heartbeat_errors"),
+			TxCompressed: f("tx_compressed"),
+		}
+	}
+	return result, nil
+}
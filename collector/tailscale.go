@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// isTailscale reports whether iface is the Tailscale TUN device. It has no
+// driver symlink or DEVTYPE, so detection goes by the "tailscale" name
+// prefix tailscaled uses.
+func isTailscale(iface string) bool {
+	return strings.HasPrefix(iface, "tailscale")
+}
+
+// tailscalePeerCount runs `tailscale status --json` and returns the number
+// of peers in the tailnet visible to this node.
+func (c *NetworkCollector) tailscalePeerCount() (int, error) {
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "tailscale", "status", "--json")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return 0, execError(ctx, c.logger, "tailscale", err)
+	}
+
+	var status struct {
+		Peer map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return 0, fmt.Errorf("tailscale status: %w", err)
+	}
+	return len(status.Peer), nil
+}
@@ -0,0 +1,240 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// topology holds the slow-changing sysfs view of the host's interfaces:
+// ifindex numbers, bridge/bond membership (including Open vSwitch bridges),
+// driver names and SR-IOV VF → PF links. It is rebuilt every
+// Options.TopologyRefresh, or immediately when the interface set changes.
+type topology struct {
+	ifaces     map[string]bool     // interface set the topology was built for
+	ifindexMap map[int]string      // ifindex → interface name
+	bridgeMap  map[string]string   // interface → parent bridge
+	bondMap    map[string]string   // interface → parent bond
+	bonds      map[string]bool     // bond master devices
+	drivers    map[string]string   // interface → driver name ("" if none)
+	devTypes   map[string]string   // interface → DEVTYPE from sysfs uevent ("" if none)
+	parents    map[string]string   // stacked interface → its single lower device
+	ovsBridges map[string]bool     // Open vSwitch bridge devices
+	uplinks    map[string]string   // bridge → physical uplink(s), comma-separated
+	kinds      map[string]linkKind // interface → rtnetlink kind (nil if netlink is unavailable)
+	physFns    map[string]string   // SR-IOV virtual function → its physical function
+	tunModes   map[string]string   // TUN/TAP device → "tun" or "tap"
+	builtAt    time.Time
+}
+
+// topologyCache guards the cached topology shared across scrapes.
+type topologyCache struct {
+	mu   sync.Mutex
+	topo *topology
+
+	// hits and misses count topology lookups served from / rebuilt for
+	// the cache.
+	hits, misses atomic.Uint64
+}
+
+// topology returns the cached topology for stats' interface set, rebuilding
+// it when the cache is disabled, expired, or the set of interfaces differs.
+func (c *NetworkCollector) topology(stats map[string]interfaceStats, sysNetPath string) *topology {
+	c.topoCache.mu.Lock()
+	defer c.topoCache.mu.Unlock()
+
+	if t := c.topoCache.topo; t != nil && c.opts.TopologyRefresh > 0 &&
+		time.Since(t.builtAt) < c.opts.TopologyRefresh && sameInterfaceSet(t.ifaces, stats) {
+		c.topoCache.hits.Add(1)
+		return t
+	}
+	c.topoCache.misses.Add(1)
+
+	t := &topology{
+		ifaces:     make(map[string]bool, len(stats)),
+		ifindexMap: c.buildIfindexMap(stats, sysNetPath),
+		bridgeMap:  c.buildBridgeMap(stats, sysNetPath),
+		bondMap:    c.buildBondMap(stats, sysNetPath),
+		bonds:      make(map[string]bool),
+		drivers:    make(map[string]string, len(stats)),
+		devTypes:   make(map[string]string, len(stats)),
+		parents:    make(map[string]string),
+		physFns:    make(map[string]string),
+		tunModes:   make(map[string]string),
+		builtAt:    time.Now(),
+	}
+	ovsPorts, ovsBridges := c.buildOVSBridgeMap(sysNetPath)
+	t.ovsBridges = ovsBridges
+	for port, br := range ovsPorts {
+		if _, ok := stats[port]; ok {
+			t.bridgeMap[port] = br
+		}
+	}
+	for iface := range stats {
+		t.ifaces[iface] = true
+		if target, err := os.Readlink(filepath.Join(sysNetPath, iface, "device", "driver")); err == nil {
+			t.drivers[iface] = filepath.Base(target)
+			if pf := readSRIOVPhysFn(sysNetPath, iface); pf != "" {
+				t.physFns[iface] = pf
+			}
+		}
+		t.devTypes[iface] = readUeventDevType(filepath.Join(sysNetPath, iface, "uevent"))
+		if mode := readTunMode(sysNetPath, iface); mode != "" {
+			t.tunModes[iface] = mode
+		}
+		switch {
+		case isBondMaster(sysNetPath, iface):
+			t.bonds[iface] = true
+		case t.devTypes[iface] == "bridge", ovsBridges[iface]:
+			// Bridge ports show up as lower devices; they are not parents.
+		default:
+			if parent := readLowerDevice(filepath.Join(sysNetPath, iface)); parent != "" {
+				t.parents[iface] = parent
+			}
+		}
+	}
+	t.uplinks = buildBridgeUplinks(stats, sysNetPath, t.devTypes, ovsPorts)
+	if kinds, err := readLinkKinds(); err == nil {
+		t.kinds = kinds
+	} else {
+		c.logger.Debug("cannot read link kinds via netlink", "error", err)
+	}
+	c.topoCache.topo = t
+	return t
+}
+
+// buildBridgeUplinks returns, for every Linux or Open vSwitch bridge in
+// stats, the physical NIC(s) carrying its traffic off the host. Members are
+// read from /sys/class/net/<bridge>/brif/ (or the OVS port list) so that
+// uplinks excluded by the interface filters are still found. A member
+// stacked on another device (e.g. a VLAN sub-interface) resolves to its
+// lower device; bonds count as the uplink themselves.
+func buildBridgeUplinks(stats map[string]interfaceStats, sysNetPath string, devTypes, ovsPorts map[string]string) map[string]string {
+	members := make(map[string][]string)
+	for port, br := range ovsPorts {
+		members[br] = append(members[br], port)
+	}
+	for iface := range stats {
+		if devTypes[iface] != "bridge" {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(sysNetPath, iface, "brif"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			members[iface] = append(members[iface], entry.Name())
+		}
+	}
+
+	uplinks := make(map[string]string, len(members))
+	for br, ports := range members {
+		var found []string
+		for _, port := range ports {
+			if uplink := resolveUplink(sysNetPath, port); uplink != "" {
+				found = append(found, uplink)
+			}
+		}
+		if len(found) > 0 {
+			sort.Strings(found)
+			uplinks[br] = strings.Join(found, ",")
+		}
+	}
+	return uplinks
+}
+
+// maxUplinkDepth bounds how many lower_<dev> links resolveUplink follows.
+const maxUplinkDepth = 4
+
+// resolveUplink follows iface's lower devices down to a physical NIC (one
+// with a device/driver symlink) or a bond master and returns its name, or
+// "" if iface is not backed by one (veths, taps).
+func resolveUplink(sysNetPath, iface string) string {
+	for range maxUplinkDepth {
+		if _, err := os.Lstat(filepath.Join(sysNetPath, iface, "device", "driver")); err == nil {
+			return iface
+		}
+		if isBondMaster(sysNetPath, iface) {
+			return iface
+		}
+		if iface = readLowerDevice(filepath.Join(sysNetPath, iface)); iface == "" {
+			return ""
+		}
+	}
+	return ""
+}
+
+// bridgeRole returns info's role within a bridge: "bridge" for the bridge
+// device, "uplink" for a port backed by a physical NIC or bond (directly or
+// through a VLAN sub-interface), "member" for any other port (veths, taps)
+// and "" for interfaces outside bridges.
+func bridgeRole(sysNetPath string, info interfaceInfo) string {
+	switch {
+	case info.InstanceType == "bridge":
+		return "bridge"
+	case info.Bridge == "":
+		return ""
+	case resolveUplink(sysNetPath, info.Name) != "":
+		return "uplink"
+	default:
+		return "member"
+	}
+}
+
+// invalidateTopology drops the cached topology so the next call rebuilds it.
+func (c *NetworkCollector) invalidateTopology() {
+	c.topoCache.mu.Lock()
+	c.topoCache.topo = nil
+	c.topoCache.mu.Unlock()
+}
+
+// sameInterfaceSet reports whether ifaces contains exactly the interfaces in stats.
+func sameInterfaceSet(ifaces map[string]bool, stats map[string]interfaceStats) bool {
+	if len(ifaces) != len(stats) {
+		return false
+	}
+	for iface := range stats {
+		if !ifaces[iface] {
+			return false
+		}
+	}
+	return true
+}
+
+// readLowerDevice returns the lower device of a stacked interface such as a
+// macvlan, macvtap, ipvlan or VLAN sub-interface, taken from its single
+// lower_<dev> sysfs link. It returns "" when there is no lower device or
+// more than one.
+func readLowerDevice(ifaceDir string) string {
+	entries, err := os.ReadDir(ifaceDir)
+	if err != nil {
+		return ""
+	}
+	lower := ""
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), "lower_")
+		if !ok {
+			continue
+		}
+		if lower != "" {
+			return ""
+		}
+		lower = name
+	}
+	return lower
+}
+
+// readUeventDevType returns the DEVTYPE value (e.g. "bridge", "wireguard",
+// "bond", "vlan") from a sysfs uevent file, or "" if absent.
+func readUeventDevType(path string) string {
+	for _, line := range strings.Split(readFileString(path), "\n") {
+		if v, ok := strings.CutPrefix(line, "DEVTYPE="); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// utilizationTracker remembers each interface's byte counters from the
+// previous scrape so Collect can derive link utilization from the delta.
+type utilizationTracker struct {
+	mu   sync.Mutex
+	prev map[string]utilizationSample // interface → last sample
+}
+
+// utilizationSample is one interface's counters and the ratios derived
+// from them. ok is false until two samples are available.
+type utilizationSample struct {
+	rxBytes, txBytes uint64
+	at               time.Time
+	rx, tx           float64
+	ok               bool
+}
+
+// linkUtilization returns the fraction of the link's capacity used in each
+// direction since the previous sample of iface. Scrapes served from the
+// same snapshot (same at) get the previously computed ratios. ok is false
+// on the first sample and after a counter reset.
+func (c *NetworkCollector) linkUtilization(iface string, s interfaceStats, at time.Time, speedMbps int64) (rx, tx float64, ok bool) {
+	c.util.mu.Lock()
+	defer c.util.mu.Unlock()
+	if c.util.prev == nil {
+		c.util.prev = make(map[string]utilizationSample)
+	}
+
+	p, seen := c.util.prev[iface]
+	if seen && at.Equal(p.at) {
+		return p.rx, p.tx, p.ok
+	}
+
+	next := utilizationSample{rxBytes: s.RxBytes, txBytes: s.TxBytes, at: at}
+	if seen && at.After(p.at) && s.RxBytes >= p.rxBytes && s.TxBytes >= p.txBytes {
+		capacity := float64(speedMbps) * 1e6 / 8 * at.Sub(p.at).Seconds() // bytes
+		next.rx = float64(s.RxBytes-p.rxBytes) / capacity
+		next.tx = float64(s.TxBytes-p.txBytes) / capacity
+		next.ok = true
+	}
+	c.util.prev[iface] = next
+	return next.rx, next.tx, next.ok
+}
+
+// pruneUtilization forgets interfaces that were not emitted this scrape.
+func (c *NetworkCollector) pruneUtilization(current map[string][]string) {
+	c.util.mu.Lock()
+	defer c.util.mu.Unlock()
+	for iface := range c.util.prev {
+		if _, ok := current[iface]; !ok {
+			delete(c.util.prev, iface)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ValidatePaths checks at startup that the files every scrape reads exist,
+// so a mistyped --path.procfs fails immediately instead of as a scrape
+// error on each scrape. Only an unreadable procfs counter source is
+// returned as an error. Everything else is logged as a warning: a host
+// counters file that falls back to the exporter's own namespace, an
+// unreadable netlink or sysfs backend, a missing sysfs (hosts such as
+// FreeBSD's linprocfs have none, at the cost of topology enrichment), and
+// a missing unix socket among dockerSockets (pass only the sockets the user
+// configured explicitly).
+func ValidatePaths(logger *slog.Logger, opts Options, dockerSockets []string) error {
+	source, hostErr, err := opts.checkCounterSource()
+	switch {
+	case err != nil && (opts.StatsBackend == "" || opts.StatsBackend == StatsBackendProcfs):
+		return fmt.Errorf("cannot read interface counters from %s (check --path.procfs): %w", source, err)
+	case err != nil:
+		logger.Warn("cannot read interface counters, scrapes will fail until they are readable",
+			"stats.backend", opts.StatsBackend, "source", source, "error", err)
+	case hostErr != nil:
+		logger.Warn("cannot read host counters, scrapes will fall back to the exporter's own network namespace",
+			"path", opts.hostProcNet("dev"), "fallback", source, "error", hostErr)
+	}
+
+	if sysNetPath := opts.sysClassNetPath(); opts.StatsBackend != StatsBackendSysfs {
+		if _, err := os.ReadDir(sysNetPath); err != nil {
+			logger.Warn("cannot list interfaces in sysfs, bridge, VLAN and driver enrichment will be missing (check --path.rootfs)",
+				"path", sysNetPath, "error", err)
+		}
+	}
+
+	for _, socket := range dockerSockets {
+		if strings.Contains(socket, "://") && !strings.HasPrefix(socket, "unix://") {
+			continue // remote daemon, checked on the first scrape
+		}
+		path := strings.TrimPrefix(socket, "unix://")
+		if _, err := os.Stat(path); err != nil {
+			logger.Warn("Docker socket not found, container mapping will be unavailable until it appears",
+				"docker.socket", path, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// containerIfindexes returns the ifindexes of the non-lo interfaces in the
+// network namespace of pid.
+//
+// Unlike /proc/<PID>/root/sys, the per-process /proc/<PID>/net files are
+// usually readable even under user namespaces or restricted mounts.
+// dev_mcast lists every interface with a multicast address (any up
+// Ethernet link) and if_inet6 every interface with an IPv6 address; the
+// union of both covers IPv4-only and IPv6-only containers. Missing files
+// are skipped; other read errors are returned along with whatever was read.
+func containerIfindexes(procPath string, pid int) (map[int]bool, error) {
+	netDir := filepath.Join(procPath, strconv.Itoa(pid), "net")
+	result := make(map[int]bool)
+
+	// dev_mcast: "<ifindex> <name> <users> <global> <address>"
+	mcastErr := readProcNetColumns(filepath.Join(netDir, "dev_mcast"), func(fields []string) {
+		if len(fields) < 2 || fields[1] == "lo" {
+			return
+		}
+		if idx, err := strconv.Atoi(fields[0]); err == nil {
+			result[idx] = true
+		}
+	})
+
+	// if_inet6: "<address> <ifindex hex> <prefix> <scope> <flags> <name>"
+	inet6Err := readProcNetColumns(filepath.Join(netDir, "if_inet6"), func(fields []string) {
+		if len(fields) < 6 || fields[5] == "lo" {
+			return
+		}
+		if idx, err := strconv.ParseInt(fields[1], 16, 32); err == nil {
+			result[int(idx)] = true
+		}
+	})
+
+	var errs []error
+	for _, err := range []error{mcastErr, inet6Err} {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// readProcNetColumns calls fn with the whitespace-separated fields of every
+// line in path. Reading stops at the first line longer than
+// maxProcLineBytes, which is reported as an error naming path.
+func readProcNetColumns(path string, fn func(fields []string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := newProcScanner(f)
+	for scanner.Scan() {
+		fn(strings.Fields(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, procScanError(err))
+	}
+	return nil
+}
+
+// matchVethPeers resolves host veths for containers whose namespace sysfs
+// could not be read. Each host veth's iflink is the ifindex of its peer
+// inside the container, so a veth is assigned to a container when exactly
+// one of the candidates has an interface with that ifindex. Ambiguous
+// matches are left unresolved rather than guessed.
+//
+// It returns the IDs of the containers that were matched to at least one veth.
+func (c *NetworkCollector) matchVethPeers(containers []ContainerInfo, ifindexMap map[int]string, vethMap map[string]ContainerInfo) map[string]bool {
+	matched := make(map[string]bool)
+	if len(containers) == 0 {
+		return matched
+	}
+
+	known := make([]map[int]bool, len(containers))
+	for i, ci := range containers {
+		ifindexes, err := containerIfindexes(c.opts.ProcPath, ci.PID)
+		if err != nil {
+			c.warnFailure("veth peers "+ci.ID, "cannot read container interfaces", "container", ci.Name, "pid", ci.PID, "error", err)
+		} else {
+			c.clearFailure("veth peers "+ci.ID, "reading container interfaces recovered", "container", ci.Name, "pid", ci.PID)
+		}
+		known[i] = ifindexes
+	}
+
+	sysNetPath := c.sysClassNetPath()
+	for _, hostIface := range ifindexMap {
+		if !strings.HasPrefix(hostIface, "veth") {
+			continue
+		}
+		if _, taken := vethMap[hostIface]; taken {
+			continue
+		}
+		peer, err := strconv.Atoi(readFileString(filepath.Join(sysNetPath, hostIface, "iflink")))
+		if err != nil {
+			continue
+		}
+
+		owner := -1
+		for i := range containers {
+			if !known[i][peer] {
+				continue
+			}
+			if owner >= 0 {
+				owner = -1
+				break
+			}
+			owner = i
+		}
+		if owner < 0 {
+			continue
+		}
+		vethMap[hostIface] = containers[owner]
+		matched[containers[owner].ID] = true
+	}
+	return matched
+}
+
+// resolveVethConflict picks the owner of hostIface when two containers both
+// report an interface whose iflink is hostIfindex.
+//
+// Containers sharing one network namespace (network_mode: container:X or
+// service:X, e.g. VPN sidecars) all see the same veth; the namespace's
+// owner is the container attached to networks of its own, and the overlap
+// is expected. Otherwise an ifindex was reused during rapid container
+// churn: the real owner's interface is also the one the host veth's own
+// iflink points back to, and if that does not single out one container,
+// the first claim is kept.
+func (c *NetworkCollector) resolveVethConflict(hostIface string, hostIfindex int, prev, next ContainerInfo) ContainerInfo {
+	if c.sameNetns(prev.PID, next.PID) {
+		owner := prev
+		if len(prev.Networks) == 0 && len(next.Networks) > 0 {
+			owner = next
+		}
+		c.logger.Debug("host veth shared by containers in one network namespace",
+			"interface", hostIface, "first", prev.Name, "second", next.Name, "assigned", owner.Name)
+		return owner
+	}
+
+	peer, err := strconv.Atoi(readFileString(filepath.Join(c.sysClassNetPath(), hostIface, "iflink")))
+	prevOwns := err == nil && c.containerHasPeer(prev.PID, hostIfindex, peer)
+	nextOwns := err == nil && c.containerHasPeer(next.PID, hostIfindex, peer)
+
+	owner := prev
+	if nextOwns && !prevOwns {
+		owner = next
+	}
+	c.logger.Warn("host veth claimed by two containers",
+		"interface", hostIface, "first", prev.Name, "second", next.Name,
+		"assigned", owner.Name, "verified", prevOwns != nextOwns)
+	return owner
+}
+
+// sameNetns reports whether two processes are in the same network
+// namespace, i.e. their /proc/<PID>/ns/net resolve to the same nsfs inode.
+func (c *NetworkCollector) sameNetns(pid1, pid2 int) bool {
+	fi1, err1 := os.Stat(filepath.Join(c.opts.ProcPath, strconv.Itoa(pid1), "ns", "net"))
+	fi2, err2 := os.Stat(filepath.Join(c.opts.ProcPath, strconv.Itoa(pid2), "ns", "net"))
+	return err1 == nil && err2 == nil && os.SameFile(fi1, fi2)
+}
+
+// containerHasPeer reports whether the network namespace of pid has an
+// interface with ifindex peer whose iflink is hostIfindex, i.e. the inside
+// end of the host veth.
+func (c *NetworkCollector) containerHasPeer(pid, hostIfindex, peer int) bool {
+	sysNet := filepath.Join(c.opts.ProcPath, strconv.Itoa(pid), "root", "sys", "class", "net")
+	entries, err := os.ReadDir(sysNet)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(sysNet, entry.Name())
+		idx, err1 := strconv.Atoi(readFileString(filepath.Join(dir, "ifindex")))
+		link, err2 := strconv.Atoi(readFileString(filepath.Join(dir, "iflink")))
+		if err1 == nil && err2 == nil && idx == peer && link == hostIfindex {
+			return true
+		}
+	}
+	return false
+}
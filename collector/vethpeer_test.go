@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// vethFixture lays out a fake host sysfs under rootfs and a fake procfs
+// under proc for resolveVethConflict.
+type vethFixture struct {
+	t           *testing.T
+	rootfs      string
+	proc        string
+	sharedNetns string
+}
+
+func newVethFixture(t *testing.T) *vethFixture {
+	f := &vethFixture{t: t, rootfs: t.TempDir(), proc: t.TempDir()}
+	f.sharedNetns = filepath.Join(t.TempDir(), "netns")
+	f.write(f.sharedNetns, "")
+	return f
+}
+
+func (f *vethFixture) write(path, content string) {
+	f.t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// hostVeth creates a host veth whose peer has ifindex peer.
+func (f *vethFixture) hostVeth(name string, peer int) {
+	f.write(filepath.Join(f.rootfs, "sys", "class", "net", name, "iflink"), strconv.Itoa(peer))
+}
+
+// container creates pid with an eth0 of the given ifindex and iflink. A
+// non-empty netns links /proc/<pid>/ns/net to that file.
+func (f *vethFixture) container(pid, ifindex, iflink int, netns string) {
+	dir := filepath.Join(f.proc, strconv.Itoa(pid))
+	eth0 := filepath.Join(dir, "root", "sys", "class", "net", "eth0")
+	f.write(filepath.Join(eth0, "ifindex"), strconv.Itoa(ifindex))
+	f.write(filepath.Join(eth0, "iflink"), strconv.Itoa(iflink))
+	nsPath := filepath.Join(dir, "ns", "net")
+	if netns == "" {
+		f.write(nsPath, "")
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(nsPath), 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.Symlink(netns, nsPath); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *vethFixture) collector(logs *bytes.Buffer) *NetworkCollector {
+	c := testCollector(f.t, Options{ProcPath: f.proc, RootfsPath: f.rootfs})
+	c.logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return c
+}
+
+// TestResolveVethConflictIfindexReuse reproduces a host ifindex reused
+// during container churn: a stale container still reports an eth0 whose
+// iflink is the host veth's ifindex, but only the new container's eth0 is
+// the veth's peer.
+func TestResolveVethConflictIfindexReuse(t *testing.T) {
+	f := newVethFixture(t)
+	f.hostVeth("veth1234", 5)   // host ifindex 10, peer ifindex 5
+	f.container(100, 7, 10, "") // stale: iflink 10, but not the veth's peer
+	f.container(200, 5, 10, "") // real owner
+	stale := ContainerInfo{ID: "a", Name: "stale", PID: 100}
+	owner := ContainerInfo{ID: "b", Name: "owner", PID: 200}
+
+	for _, order := range [][2]ContainerInfo{{stale, owner}, {owner, stale}} {
+		var logs bytes.Buffer
+		got := f.collector(&logs).resolveVethConflict("veth1234", 10, order[0], order[1])
+		if got.ID != owner.ID {
+			t.Errorf("first=%s: assigned %s, want %s", order[0].Name, got.Name, owner.Name)
+		}
+		if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "verified=true") {
+			t.Errorf("first=%s: want a verified warning, got %q", order[0].Name, logs.String())
+		}
+	}
+}
+
+func TestResolveVethConflictUnverified(t *testing.T) {
+	f := newVethFixture(t)
+	f.hostVeth("veth1234", 5)
+	f.container(100, 7, 10, "")
+	f.container(200, 8, 10, "")
+	first := ContainerInfo{ID: "a", Name: "first", PID: 100}
+	second := ContainerInfo{ID: "b", Name: "second", PID: 200}
+
+	var logs bytes.Buffer
+	if got := f.collector(&logs).resolveVethConflict("veth1234", 10, first, second); got.ID != first.ID {
+		t.Errorf("assigned %s, want the first claim", got.Name)
+	}
+	if !strings.Contains(logs.String(), "verified=false") {
+		t.Errorf("want an unverified warning, got %q", logs.String())
+	}
+}
+
+// TestResolveVethConflictSharedNetns covers network_mode: container:X,
+// where both containers legitimately see the veth.
+func TestResolveVethConflictSharedNetns(t *testing.T) {
+	f := newVethFixture(t)
+	f.hostVeth("veth1234", 5)
+	f.container(100, 5, 10, f.sharedNetns)
+	f.container(200, 5, 10, f.sharedNetns)
+	vpn := ContainerInfo{ID: "a", Name: "gluetun", PID: 100, Networks: map[string]ContainerNetwork{"ix-vpn_default": {}}}
+	sidecar := ContainerInfo{ID: "b", Name: "qbittorrent", PID: 200}
+
+	for _, order := range [][2]ContainerInfo{{vpn, sidecar}, {sidecar, vpn}} {
+		var logs bytes.Buffer
+		got := f.collector(&logs).resolveVethConflict("veth1234", 10, order[0], order[1])
+		if got.ID != vpn.ID {
+			t.Errorf("first=%s: assigned %s, want the namespace owner %s", order[0].Name, got.Name, vpn.Name)
+		}
+		if strings.Contains(logs.String(), "level=WARN") {
+			t.Errorf("first=%s: shared namespace logged a warning: %q", order[0].Name, logs.String())
+		}
+	}
+}
+
+// TestMatchVethPeersWarnsOnce checks that an unreadable /proc/<pid>/net is
+// warned about once, repeated at debug level, and cleared on recovery.
+func TestMatchVethPeersWarnsOnce(t *testing.T) {
+	f := newVethFixture(t)
+	mcast := filepath.Join(f.proc, "100", "net", "dev_mcast")
+	f.write(mcast, "7    eth0            1     0     01005e000001\n"+longLine+"\n")
+	var logs bytes.Buffer
+	c := f.collector(&logs)
+	ci := ContainerInfo{ID: "a", Name: "web", PID: 100}
+
+	for range 3 {
+		c.matchVethPeers([]ContainerInfo{ci}, map[int]string{}, map[string]ContainerInfo{})
+	}
+	if n := strings.Count(logs.String(), "level=WARN msg=\"cannot read container interfaces\""); n != 1 {
+		t.Errorf("logged %d warnings, want 1:\n%s", n, logs.String())
+	}
+	if n := strings.Count(logs.String(), "level=DEBUG msg=\"cannot read container interfaces\""); n != 2 {
+		t.Errorf("logged %d debug repeats, want 2:\n%s", n, logs.String())
+	}
+
+	f.write(mcast, "7    eth0            1     0     01005e000001\n")
+	c.matchVethPeers([]ContainerInfo{ci}, map[int]string{}, map[string]ContainerInfo{})
+	if !strings.Contains(logs.String(), "level=INFO msg=\"reading container interfaces recovered\"") {
+		t.Errorf("no recovery message logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tun_flags bits from <linux/if_tun.h>.
+const (
+	iffTun = 0x0001
+	iffTap = 0x0002
+)
+
+// readTunMode returns "tun" or "tap" for a TUN/TAP device, taken from its
+// sysfs tun_flags attribute, or "" for any other interface.
+func readTunMode(sysNetPath, iface string) string {
+	flags, err := strconv.ParseUint(strings.TrimPrefix(readFileString(filepath.Join(sysNetPath, iface, "tun_flags")), "0x"), 16, 32)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case flags&iffTun != 0:
+		return "tun"
+	case flags&iffTap != 0:
+		return "tap"
+	}
+	return ""
+}
+
+// vpnDaemons are the process names (from /proc/<PID>/comm) whose TUN/TAP
+// devices are classified as VPN tunnels.
+var vpnDaemons = map[string]bool{"openvpn": true}
+
+// findVPNOwners maps each of the given TUN/TAP interfaces that is held open
+// by a VPN daemon to that daemon's name. Only daemon processes have their
+// fds scanned; the rest of /proc costs one comm read per process.
+func (c *NetworkCollector) findVPNOwners(candidates map[string]bool) map[string]string {
+	owners := make(map[string]string)
+	if len(candidates) == 0 {
+		return owners
+	}
+	entries, err := os.ReadDir(c.opts.ProcPath)
+	if err != nil {
+		c.logger.Debug("cannot read procfs for VPN processes", "error", err)
+		return owners
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		comm := readFileString(filepath.Join(c.opts.ProcPath, entry.Name(), "comm"))
+		if !vpnDaemons[comm] {
+			continue
+		}
+		fdDir := filepath.Join(c.opts.ProcPath, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			c.logger.Debug("cannot read VPN process fd dir", "pid", entry.Name(), "error", err)
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err != nil || target != "/dev/net/tun" {
+				continue
+			}
+			iff := readFdinfoIff(filepath.Join(c.opts.ProcPath, entry.Name(), "fdinfo", fd.Name()))
+			if candidates[iff] {
+				owners[iff] = comm
+			}
+		}
+	}
+	return owners
+}
@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// isWireGuard reports whether iface is a WireGuard device, using the
+// DEVTYPE from its sysfs uevent and falling back to the conventional "wg"
+// name prefix.
+func isWireGuard(iface, devType string) bool {
+	return devType == "wireguard" || strings.HasPrefix(iface, "wg")
+}
+
+// wireGuardPeerCount runs `wg show <iface> dump` and returns the number of
+// peers configured on the interface.
+//
+// The dump's first line describes the interface itself; every following
+// line is one peer.
+func (c *NetworkCollector) wireGuardPeerCount(iface string) (int, error) {
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "wg", "show", iface, "dump")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return 0, execError(ctx, c.logger, "wg", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			lines++
+		}
+	}
+	if lines == 0 {
+		return 0, nil
+	}
+	return lines - 1, nil
+}
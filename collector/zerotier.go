@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// isZeroTier reports whether iface is a ZeroTier virtual port. zerotier-one
+// creates plain TAP devices named "zt" plus a hash of the network ID, so
+// detection goes by name prefix.
+func isZeroTier(iface string) bool {
+	return strings.HasPrefix(iface, "zt")
+}
+
+// zeroTierNetwork is one joined ZeroTier network.
+type zeroTierNetwork struct {
+	ID   string
+	Name string
+}
+
+// zeroTierNetworks runs `zerotier-cli -j listnetworks` and maps each port
+// device name to the network it belongs to.
+func (c *NetworkCollector) zeroTierNetworks() (map[string]zeroTierNetwork, error) {
+	ctx, cancel := c.execContext()
+	defer cancel()
+	cmd := c.buildCommand(ctx, "zerotier-cli", "-j", "listnetworks")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return nil, execError(ctx, c.logger, "zerotier-cli", err)
+	}
+	return parseZeroTierNetworks(out.Bytes())
+}
+
+// parseZeroTierNetworks decodes zerotier-cli's JSON network list:
+//
+//	[{"id": "8056c2e21c000001", "name": "earth",
+//	  "portDeviceName": "ztmjfcpubr", ...}, ...]
+func parseZeroTierNetworks(data []byte) (map[string]zeroTierNetwork, error) {
+	var networks []struct {
+		ID             string `json:"id"`
+		Name           string `json:"name"`
+		PortDeviceName string `json:"portDeviceName"`
+	}
+	if err := json.Unmarshal(data, &networks); err != nil {
+		return nil, fmt.Errorf("zerotier-cli listnetworks: %w", err)
+	}
+	result := make(map[string]zeroTierNetwork, len(networks))
+	for _, n := range networks {
+		if n.PortDeviceName == "" {
+			continue
+		}
+		result[n.PortDeviceName] = zeroTierNetwork{ID: n.ID, Name: n.Name}
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// applyConfigFile reads a configuration file with one "flag.name = value"
+// entry per line and applies each to fs, skipping flags that were given on
+// the command line so those take precedence. Keys are flag names without
+// leading dashes; repeatable flags may appear several times. Blank lines
+// and lines starting with "#" are ignored.
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	onCommandLine := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { onCommandLine[fl.Name] = true })
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: expected flag.name = value", path, lineNo)
+		}
+		if key == "config.file" || key == "version" || fs.Lookup(key) == nil {
+			return fmt.Errorf("%s:%d: unknown setting %q", path, lineNo, key)
+		}
+		if onCommandLine[key] {
+			continue
+		}
+		if err := fs.Set(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", path, lineNo, key, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// envPrefix is prepended to a flag's name to form its environment variable.
+const envPrefix = "TRUENAS_NET_"
+
+// envName maps a flag name to its environment variable, e.g.
+// "web.listen-address" → "TRUENAS_NET_WEB_LISTEN_ADDRESS".
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flagName))
+}
+
+// applyEnv sets every flag of fs that was not given on the command line from
+// its environment variable, if present. Repeatable flags take a
+// comma-separated list. Call it before applyConfigFile so the environment
+// takes precedence over the file.
+func applyEnv(fs *flag.FlagSet) error {
+	onCommandLine := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { onCommandLine[fl.Name] = true })
+
+	var err error
+	fs.VisitAll(func(fl *flag.Flag) {
+		if err != nil || onCommandLine[fl.Name] || fl.Name == "version" {
+			return
+		}
+		value, ok := os.LookupEnv(envName(fl.Name))
+		if !ok {
+			return
+		}
+		values := []string{value}
+		if _, repeatable := fl.Value.(*stringList); repeatable {
+			values = splitList(value)
+		}
+		for _, v := range values {
+			if setErr := fs.Set(fl.Name, v); setErr != nil {
+				err = fmt.Errorf("%s: %w", envName(fl.Name), setErr)
+				return
+			}
+		}
+	})
+	return err
+}
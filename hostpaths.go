@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+)
+
+// hostMount is where the container images and compose examples mount the
+// host's root filesystem.
+const hostMount = "/host"
+
+// containerMarkers are files container runtimes create in every container.
+var containerMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+
+// runningInContainer reports whether the exporter appears to run inside a
+// container.
+func runningInContainer() bool {
+	for _, marker := range containerMarkers {
+		if pathExists(marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathExists reports whether path can be stat'ed.
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// detectHostPaths points --path.procfs, --path.rootfs and --docker.socket
+// at the host mount when it is present and the flags were left at their
+// defaults (not set on the command line, in the environment or in the
+// config file). Inside a container with no host mount it warns instead,
+// since the container's own /proc and / would be read; explicitly set
+// paths are trusted.
+func detectHostPaths(logger *slog.Logger, fs *flag.FlagSet, procPath, rootfsPath *string, dockerSockets *stringList) {
+	set := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	if !set["path.procfs"] && *procPath == "/proc" && pathExists(hostMount+"/proc/1/net/dev") {
+		*procPath = hostMount + "/proc"
+		logger.Info("host procfs found, using it", "path.procfs", *procPath)
+	}
+	if !set["path.rootfs"] && *rootfsPath == "/" && pathExists(hostMount+"/sys/class/net") {
+		*rootfsPath = hostMount
+		logger.Info("host root filesystem found, using it", "path.rootfs", *rootfsPath)
+	}
+	if !set["docker.socket"] && !pathExists("/var/run/docker.sock") && pathExists(hostMount+"/var/run/docker.sock") {
+		*dockerSockets = stringList{hostMount + "/var/run/docker.sock"}
+		logger.Info("host Docker socket found, using it", "docker.socket", dockerSockets.String())
+	}
+
+	defaultProc := !set["path.procfs"] && *procPath == "/proc"
+	defaultRoot := !set["path.rootfs"] && *rootfsPath == "/"
+	if runningInContainer() && (defaultProc || defaultRoot) {
+		logger.Warn("running inside a container but reading the container's own /proc or /; container, VM and bridge mapping will be wrong. Mount the host root at /host and set --path.procfs=/host/proc --path.rootfs=/host --docker.socket=/host/var/run/docker.sock",
+			"path.procfs", *procPath, "path.rootfs", *rootfsPath)
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/lfventura/prometheus-truenas-net-exporter/collector"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,22 +19,108 @@ import (
 )
 
 var (
-	version = "dev"
+	version   = "dev"
+	buildDate = "unknown"
 )
 
+// shutdownGracePeriod is how long in-flight scrapes may take to finish after
+// SIGINT/SIGTERM before the server is closed.
+const shutdownGracePeriod = 5 * time.Second
+
 func main() {
+	configFile := flag.String("config.file", "", "File of flag.name = value settings, one per line; flags given on the command line take precedence.")
 	listenAddr := flag.String("web.listen-address", ":9551", "Address to listen on for metrics.")
 	metricsPath := flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
 	procPath := flag.String("path.procfs", "/proc", "procfs mount point (use /host/proc when running inside a container).")
+	hostPID := flag.Int("path.host-pid", 1, "PID of a process in the host network namespace, as seen in --path.procfs; its net/ files are read instead of /proc/1/net.")
 	rootfsPath := flag.String("path.rootfs", "/", "Root filesystem mount point (use /host when running inside a container). Used for chroot to run virsh.")
-	dockerSocket := flag.String("docker.socket", "/var/run/docker.sock", "Path to Docker socket for container network mapping. In container mode, use /host/var/run/docker.sock.")
+	var dockerSockets stringList
+	flag.Var(&dockerSockets, "docker.socket", "Docker endpoint for container network mapping: a unix socket path (in container mode, use /host/var/run/docker.sock) or tcp://host:port for a remote daemon. Repeat for multiple daemons (default /var/run/docker.sock).")
+	refreshInterval := flag.Duration("collector.refresh-interval", 0, "Refresh counters in the background on this interval and serve scrapes from the cached snapshot (0 = collect on every scrape).")
+	scrapeDedupeWindow := flag.Duration("collector.scrape-dedupe-window", 0, "Reuse an on-demand collection for scrapes arriving within this window (concurrent scrapes always share one collection).")
+	enrichmentTTL := flag.Duration("collector.enrichment-ttl", time.Minute, "How long Docker/VM/Incus enrichment is reused in background refresh mode before being rebuilt.")
+	ifaceInclude := flag.String("collector.interface-include", "", "Regular expression of interface names to collect (empty = all).")
+	ifaceExclude := flag.String("collector.interface-exclude", "", "Regular expression of interface names to skip. Takes precedence over --collector.interface-include.")
+	skipZeroDown := flag.Bool("collector.skip-zero-down", false, "Omit interfaces that are down and have never received or transmitted a byte.")
+	var netns stringList
+	flag.Var(&netns, "collector.netns", "Named network namespace (from /run/netns) whose interfaces are also collected. Repeat for several; adds a netns label to every series.")
+	var snmpTargets stringList
+	flag.Var(&snmpTargets, "collector.snmp-target", "Switch (host or host:port) whose IF-MIB 64-bit octet counters are also exported, with instance set to the target. Repeat for several; adds a source label to every interface series.")
+	snmpVersion := flag.String("snmp.version", "2c", "SNMP version for --collector.snmp-target: 2c or 3.")
+	snmpCommunity := flag.String("snmp.community", "public", "SNMPv2c community.")
+	snmpUser := flag.String("snmp.user", "", "SNMPv3 user name.")
+	snmpAuthProtocol := flag.String("snmp.auth-protocol", "SHA", "SNMPv3 authentication protocol: SHA or MD5.")
+	snmpAuthPassword := flag.String("snmp.auth-password", "", "SNMPv3 authentication password (empty = noAuthNoPriv).")
+	snmpPrivProtocol := flag.String("snmp.priv-protocol", "", "SNMPv3 privacy protocol: AES, or empty for none.")
+	snmpPrivPassword := flag.String("snmp.priv-password", "", "SNMPv3 privacy password.")
+	snmpTimeout := flag.Duration("snmp.timeout", 5*time.Second, "Time allowed for walking one SNMP target.")
+	var appPrefixStrip stringList
+	flag.Var(&appPrefixStrip, "collector.app-prefix-strip", "Prefix stripped from compose project, container and Docker network names to derive the app label. Repeat for several; pass an empty value to strip nothing (default ix-).")
+	appInclude := flag.String("collector.app-include", "", "Comma-separated Docker/Podman apps (compose projects) to report individually; other container interfaces are summed into app=\"other\" (empty = all).")
+	counters := flag.String("collector.counters", "all", "Comma-separated per-interface counters to emit (e.g. rx_bytes,tx_bytes,rx_errors), or \"all\".")
+	typeInclude := flag.String("collector.instance-type-include", "", "Comma-separated instance types to emit (e.g. physical,bond,vlan; empty = all). Filtered interfaces are still enriched.")
+	dockerCacheTTL := flag.Duration("docker.cache-ttl", 30*time.Second, "How long Docker container inspect results are cached (0 = disable).")
+	dockerInspectConcurrency := flag.Int("docker.inspect-concurrency", 8, "Maximum number of Docker container inspect requests run in parallel.")
+	dockerMaxRetries := flag.Int("docker.max-retries", 2, "Retries for Docker list/inspect requests that fail transiently (timeouts, HTTP 5xx), with exponential backoff. A refused connection is never retried.")
+	incusSocket := flag.String("incus.socket", "", "Path to the Incus/LXD API socket (e.g. /var/run/incus/unix.socket). Empty resolves Incus containers by scanning /proc cgroups.")
+	containerdSocket := flag.String("containerd.socket", "", "Path to the containerd socket (e.g. /run/containerd/containerd.sock), used for container mapping when no Docker/Podman endpoint answers. Empty disables it.")
+	podmanSocket := flag.String("podman.socket", "", "Path to Podman's Docker-compatible API socket (e.g. /run/podman/podman.sock). Empty disables Podman mapping.")
+	tlsCert := flag.String("web.tls-cert", "", "Path to a TLS certificate. Serves HTTPS when set together with --web.tls-key.")
+	tlsKey := flag.String("web.tls-key", "", "Path to the TLS private key for --web.tls-cert.")
+	webDebug := flag.Bool("web.debug", false, "Serve /debug/interfaces, a JSON dump of the resolved interface map and its intermediate mappings. Not for production.")
+	maxRequests := flag.Int("web.max-requests", 40, "Maximum number of concurrent scrape requests; further requests get HTTP 503 (0 = no limit).")
+	webTimeout := flag.Duration("web.timeout", 0, "Time after which a scrape request is answered with HTTP 503 (0 = no timeout).")
+	basicAuthFile := flag.String("web.basic-auth-file", "", "Path to a file of user:bcrypthash lines. When set, the metrics endpoint requires basic auth.")
+	statsBackend := flag.String("stats.backend", collector.StatsBackendProcfs, "Interface counter source: procfs (/proc/1/net/dev), netlink (RTM_GETLINK in the exporter's network namespace; run with host networking) or sysfs (/sys/class/net/<iface>/statistics).")
+	macLabel := flag.Bool("collector.mac-label", false, "Add a mac label with the interface's hardware address (raises cardinality).")
+	disableDocker := flag.Bool("collector.disable-docker", false, "Skip Docker container/network mapping (Podman is still queried when --podman.socket is set).")
+	disableVM := flag.Bool("collector.disable-vm", false, "Skip VM mapping (no midclt/virsh/QEMU/bhyve lookups).")
+	disableIncus := flag.Bool("collector.disable-incus", false, "Skip Incus/LXC container mapping.")
+	disableVLAN := flag.Bool("collector.disable-vlan", false, "Skip VLAN detection from /proc/net/vlan/config.")
+	classifyRules := flag.String("collector.classify-rules", "", "Path to a file of \"<regexp> <instance_type> [instance=<name>] [app=<name>]\" lines overriding the built-in interface classification; the first matching rule wins.")
+	containerPerspective := flag.Bool("collector.container-perspective", false, "Swap rx/tx on container interfaces to report traffic from the container's point of view, adding a perspective label.")
+	uplinkLabel := flag.Bool("collector.uplink-label", false, "Add an uplink label with the physical NIC or bond behind each interface's bridge.")
+	driverLabel := flag.Bool("collector.driver-label", false, "Add a driver label with the kernel driver of physical NICs (e.g. ixgbe, mlx5_core).")
+	netDriverLabel := flag.Bool("collector.net-driver-label", false, "Add a net_driver label with the Docker/Podman network driver of bridges and macvlan/ipvlan parent interfaces (e.g. bridge, macvlan).")
+	serviceLabel := flag.Bool("collector.service-label", false, "Add a service label with the Docker Compose service of container veths (raises cardinality).")
+	ipLabel := flag.Bool("collector.ip-label", false, "Add an ip label with the container's address on container veths (raises cardinality).")
+	containerTotals := flag.Bool("collector.container-totals", false, "Emit net_container_* counters summed across all interfaces of each container.")
+	gaugeSnapshot := flag.Bool("collector.gauge-snapshot", false, "Also emit net_interface_{rx,tx}_bytes_current gauges with the current byte counters, for interfaces too short-lived for rate().")
+	utilization := flag.Bool("collector.utilization", false, "Emit net_interface_utilization_ratio from byte deltas between scrapes and the link speed (keeps state between scrapes).")
+	aggregateVLANs := flag.Bool("collector.aggregate-vlans", false, "Emit net_vlan_* counters summed across all interfaces of each VLAN ID.")
+	nftablesEnabled := flag.Bool("collector.nftables", false, "Expose byte/packet counters of commented nftables rules from nft -j list ruleset.")
+	ethtoolEnabled := flag.Bool("collector.ethtool", false, "Expose ethtool -S driver statistics for physical interfaces (runs ethtool per NIC on each scrape).")
+	topologyRefresh := flag.Duration("collector.topology-refresh", time.Minute, "How long the sysfs topology (ifindex, bridge membership, drivers) is cached between scrapes (0 = re-read every scrape).")
+	dockerTLSCert := flag.String("docker.tls-cert", "", "Client certificate for a tcp:// Docker endpoint (enables HTTPS with --docker.tls-key).")
+	dockerTLSKey := flag.String("docker.tls-key", "", "Client private key for a tcp:// Docker endpoint.")
+	dockerTLSCA := flag.String("docker.tls-ca", "", "CA bundle used to verify a tcp:// Docker endpoint.")
+	execTimeout := flag.Duration("exec.timeout", 5*time.Second, "Deadline for each external command (midclt, virsh, wg, ethtool); 0 disables it.")
+	execPath := flag.String("exec.path", "", "Colon-separated directories searched for external commands (ethtool, wg, ovs-vsctl, ...) before the standard PATH; inside --path.rootfs in container mode.")
+	metricNamespace := flag.String("metric.namespace", "", "Prefix prepended to all exporter metric names (e.g. truenas → truenas_net_interface_rx_bytes_total).")
 	showVersion := flag.Bool("version", false, "Print version and exit.")
 	logLevel := flag.String("log.level", "info", "Log level: debug, info, warn, error.")
+	logFormat := flag.String("log.format", "text", "Log format: text or json.")
 
 	flag.Parse()
+	if err := applyEnv(flag.CommandLine); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid environment setting: %v\n", err)
+		os.Exit(1)
+	}
+	if *configFile != "" {
+		if err := applyConfigFile(flag.CommandLine, *configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot load --config.file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if len(dockerSockets) == 0 {
+		dockerSockets = stringList{"/var/run/docker.sock"}
+	}
+	if len(appPrefixStrip) == 0 {
+		appPrefixStrip = stringList{"ix-"}
+	}
 
 	if *showVersion {
-		fmt.Printf("truenas-net-exporter version %s\n", version)
+		fmt.Printf("truenas-net-exporter version %s (built %s)\n", version, buildDate)
 		os.Exit(0)
 	}
 
@@ -44,44 +136,238 @@ func main() {
 	default:
 		level = slog.LevelInfo
 	}
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch *logFormat {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --log.format %q (want text or json)\n", *logFormat)
+		os.Exit(1)
+	}
+	logger := slog.New(handler)
+	detectHostPaths(logger, flag.CommandLine, procPath, rootfsPath, &dockerSockets)
 
 	logger.Info("starting truenas-net-exporter",
 		"version", version,
 		"listen", *listenAddr,
 		"path.procfs", *procPath,
+		"stats.backend", *statsBackend,
 		"path.rootfs", *rootfsPath,
-		"docker.socket", *dockerSocket,
+		"docker.socket", dockerSockets.String(),
+		"podman.socket", *podmanSocket,
+		"collector.refresh-interval", *refreshInterval,
 	)
 
 	// Build collector options from flags.
 	opts := collector.Options{
-		ProcPath:   *procPath,
-		RootfsPath: *rootfsPath,
+		ProcPath:                 *procPath,
+		HostPID:                  *hostPID,
+		RootfsPath:               *rootfsPath,
+		RefreshInterval:          *refreshInterval,
+		EnrichmentTTL:            *enrichmentTTL,
+		ScrapeDedupeWindow:       *scrapeDedupeWindow,
+		InterfaceInclude:         *ifaceInclude,
+		InterfaceExclude:         *ifaceExclude,
+		InstanceTypeInclude:      splitList(*typeInclude),
+		Counters:                 counterList(*counters),
+		AppInclude:               splitList(*appInclude),
+		ClassifyRules:            *classifyRules,
+		SkipZeroDown:             *skipZeroDown,
+		Netns:                    netns,
+		AppPrefixStrip:           nonEmpty(appPrefixStrip),
+		DockerCacheTTL:           *dockerCacheTTL,
+		DockerInspectConcurrency: *dockerInspectConcurrency,
+		DockerMaxRetries:         *dockerMaxRetries,
+		PodmanSocket:             *podmanSocket,
+		ContainerdSocket:         *containerdSocket,
+		IncusSocket:              *incusSocket,
+		StatsBackend:             *statsBackend,
+		MACLabel:                 *macLabel,
+		IPLabel:                  *ipLabel,
+		ServiceLabel:             *serviceLabel,
+		DriverLabel:              *driverLabel,
+		NetDriverLabel:           *netDriverLabel,
+		UplinkLabel:              *uplinkLabel,
+		ContainerPerspective:     *containerPerspective,
+		DisableDocker:            *disableDocker,
+		DisableVM:                *disableVM,
+		DisableIncus:             *disableIncus,
+		DisableVLAN:              *disableVLAN,
+		ContainerTotals:          *containerTotals,
+		AggregateVLANs:           *aggregateVLANs,
+		Utilization:              *utilization,
+		GaugeSnapshot:            *gaugeSnapshot,
+		TopologyRefresh:          *topologyRefresh,
+		DockerTLSCert:            *dockerTLSCert,
+		DockerTLSKey:             *dockerTLSKey,
+		DockerTLSCA:              *dockerTLSCA,
+		ExecTimeout:              *execTimeout,
+		ExecPath:                 filepath.SplitList(*execPath),
+		SNMPTargets:              snmpTargets,
+		SNMPVersion:              *snmpVersion,
+		SNMPCommunity:            *snmpCommunity,
+		SNMPUser:                 *snmpUser,
+		SNMPAuthProtocol:         *snmpAuthProtocol,
+		SNMPAuthPassword:         *snmpAuthPassword,
+		SNMPPrivProtocol:         *snmpPrivProtocol,
+		SNMPPrivPassword:         *snmpPrivPassword,
+		SNMPTimeout:              *snmpTimeout,
+		MetricNamespace:          *metricNamespace,
 	}
 
+	// The default Docker socket is optional (Docker may not be installed);
+	// one given explicitly is expected to exist.
+	var explicitSockets []string
+	flag.Visit(func(fl *flag.Flag) {
+		if fl.Name == "docker.socket" && !*disableDocker {
+			explicitSockets = dockerSockets
+		}
+	})
+	if err := collector.ValidatePaths(logger, opts, explicitSockets); err != nil {
+		logger.Error("invalid path configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// ctx is cancelled on SIGINT/SIGTERM, aborting in-flight collector work.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	networkCollector, err := collector.NewNetworkCollector(ctx, logger, opts, dockerSockets)
+	if err != nil {
+		logger.Error("failed to create network collector", "error", err)
+		os.Exit(1)
+	}
+
+	// Start background refresh (no-op when refresh interval is 0).
+	go networkCollector.Run(ctx)
+
 	// Register collectors.
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(
 		prometheus.NewGoCollector(),
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
-		collector.NewNetworkCollector(logger, opts, *dockerSocket),
+		networkCollector,
+		collector.NewBuildInfoCollector(*metricNamespace, version, buildDate),
 	)
+	if *nftablesEnabled {
+		if _, err := opts.FindTool("nft"); err != nil {
+			logger.Warn("nftables collector enabled but nft not found; its metrics will be empty", "error", err)
+		}
+		reg.MustRegister(collector.NewNFTablesCollector(ctx, logger, opts))
+	}
+	if *ethtoolEnabled {
+		if _, err := opts.FindTool("ethtool"); err != nil {
+			logger.Warn("ethtool collector enabled but ethtool not found; its metrics will be empty", "error", err)
+		}
+		ethtoolCollector, err := collector.NewEthtoolCollector(ctx, logger, opts)
+		if err != nil {
+			logger.Error("failed to create ethtool collector", "error", err)
+			os.Exit(1)
+		}
+		reg.MustRegister(ethtoolCollector)
+	}
+
+	if (*tlsCert == "") != (*tlsKey == "") {
+		logger.Error("--web.tls-cert and --web.tls-key must be set together")
+		os.Exit(1)
+	}
 
-	http.Handle(*metricsPath, promhttp.HandlerFor(reg, promhttp.HandlerOpts{
-		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
-	}))
+	var metricsHandler http.Handler = promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+		ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		MaxRequestsInFlight: *maxRequests,
+		Timeout:             *webTimeout,
+	})
+	var debugHandler http.Handler = debugInterfacesHandler(networkCollector)
+	if *basicAuthFile != "" {
+		users, err := loadBasicAuthFile(*basicAuthFile)
+		if err != nil {
+			logger.Error("failed to load basic auth file", "error", err)
+			os.Exit(1)
+		}
+		metricsHandler = basicAuth(users, metricsHandler)
+		debugHandler = basicAuth(users, debugHandler)
+		logger.Info("basic auth enabled for metrics endpoint", "users", len(users))
+	}
+	http.Handle(*metricsPath, metricsHandler)
+	http.HandleFunc("/healthz", healthzHandler(networkCollector))
+	if *webDebug {
+		http.Handle("/debug/interfaces", debugHandler)
+		logger.Warn("debug endpoint enabled", "path", "/debug/interfaces")
+	}
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		fmt.Fprintf(w, `<html><head><title>TrueNAS Network Exporter</title></head>
 <body><h1>TrueNAS Network Exporter</h1>
 <p><a href="%s">Metrics</a></p>
+<p><a href="/healthz">Health</a></p>
 </body></html>`, *metricsPath)
 	})
 
-	logger.Info("listening", "address", *listenAddr)
-	if err := http.ListenAndServe(*listenAddr, nil); err != nil {
+	server := &http.Server{Addr: *listenAddr}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "address", *listenAddr, "tls", *tlsCert != "")
+		if *tlsCert != "" {
+			serveErr <- server.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err = <-serveErr:
 		logger.Error("http server error", "error", err)
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", "grace_period", shutdownGracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("http server shutdown error", "error", err)
+	}
+}
+
+// counterList parses --collector.counters; "all" selects every counter.
+func counterList(s string) []string {
+	if strings.TrimSpace(s) == "all" {
+		return nil
 	}
+	return splitList(s)
+}
+
+// nonEmpty returns items without empty strings.
+func nonEmpty(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
 }
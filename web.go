@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lfventura/prometheus-truenas-net-exporter/collector"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loadBasicAuthFile reads a basic-auth credentials file with one
+// "user:bcrypthash" entry per line. Blank lines and lines starting with
+// "#" are ignored.
+func loadBasicAuthFile(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := make(map[string][]byte)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("%s:%d: expected user:bcrypthash", path, lineNo)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid bcrypt hash for user %q: %w", path, lineNo, user, err)
+		}
+		users[user] = []byte(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%s: no users defined", path)
+	}
+	return users, nil
+}
+
+// debugInterfacesHandler runs a fresh enrichment pass and returns the
+// resolved interface map and its intermediate mappings as indented JSON.
+func debugInterfacesHandler(nc *collector.NetworkCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		trace, err := nc.TraceEnrichment()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		out, err := json.MarshalIndent(trace, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	}
+}
+
+// basicAuth wraps next so that requests must carry HTTP basic-auth
+// credentials matching one of users.
+func basicAuth(users map[string][]byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if ok {
+			if hash, known := users[user]; known && bcrypt.CompareHashAndPassword(hash, []byte(pass)) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="truenas-net-exporter"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// healthzHandler reports 200 when the counter source is readable and 503
+// otherwise.
+// Unreachable container runtimes are listed as warnings but do not fail
+// the check, since they only provide optional enrichment.
+func healthzHandler(nc *collector.NetworkCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := nc.Health()
+
+		var b strings.Builder
+		if status.Healthy() {
+			fmt.Fprintf(&b, "ok: %s readable\n", status.CounterSource)
+		} else {
+			fmt.Fprintf(&b, "error: %s: %v\n", status.CounterSource, status.CounterErr)
+		}
+		if status.Healthy() && status.HostCountersErr != nil {
+			fmt.Fprintf(&b, "warning: host counters unreadable (%v), reading the exporter's own network namespace\n", status.HostCountersErr)
+		}
+
+		runtimes := make([]string, 0, len(status.Runtimes))
+		for name := range status.Runtimes {
+			runtimes = append(runtimes, name)
+		}
+		sort.Strings(runtimes)
+		for _, name := range runtimes {
+			if !status.Runtimes[name] {
+				fmt.Fprintf(&b, "warning: %s socket unavailable, container enrichment disabled\n", name)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !status.Healthy() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprint(w, b.String())
+	}
+}